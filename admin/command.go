@@ -0,0 +1,239 @@
+package admin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/auth"
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	description := `Extend a period, pushing back the periods after it:
+    mov admin period extend voting 1d
+
+Close a period now, or open one by closing the period before it:
+    mov admin period close suggesting
+    mov admin period open voting
+
+Skip this week's movie night, for holidays:
+    mov admin week skip
+
+Show this week's schedule and overrides:
+    mov admin week show
+
+	Only admins may override periods. Overrides only apply to the current week.
+`
+
+	return &cli.Command{
+		Name:        "admin",
+		Usage:       "overrides this week's periods",
+		Description: description,
+		Subcommands: []*cli.Command{
+			{
+				Name:  "period",
+				Usage: "changes when periods close",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "extend",
+						Usage:     "Extends a period by an amount of time such as 1d or 12h",
+						ArgsUsage: "<period> <duration>",
+						Action:    extendPeriodAction,
+					},
+					{
+						Name:      "close",
+						Usage:     "Closes a period now",
+						ArgsUsage: "<period>",
+						Action:    closePeriodAction,
+					},
+					{
+						Name:      "open",
+						Usage:     "Opens a period now",
+						ArgsUsage: "<period>",
+						Action:    openPeriodAction,
+					},
+				},
+			},
+			{
+				Name:  "week",
+				Usage: "manages this week",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "skip",
+						Usage:  "Skips this week's movie night",
+						Action: skipWeekAction,
+					},
+					{
+						Name:   "show",
+						Usage:  "Shows this week's schedule and overrides",
+						Action: showWeekAction,
+					},
+				},
+			},
+		},
+	}
+}
+
+func extendPeriodAction(c *cli.Context) error {
+	if allowed, err := auth.Require(c, auth.OverridePeriods); !allowed {
+		return err
+	}
+
+	if c.NArg() < 2 {
+		_, writeErr := c.App.Writer.Write([]byte("Period and amount of time not provided as arguments.\n"))
+		return writeErr
+	}
+
+	period, periodErr := general.ParsePeriodName(c.Args().Get(0))
+	if periodErr == nil && period == general.Sleep {
+		periodErr = fmt.Errorf("sleep lasts until the next week starts and can't be extended")
+	}
+
+	if periodErr != nil {
+		_, writeErr := c.App.Writer.Write([]byte(periodErr.Error() + "\n"))
+		return writeErr
+	}
+
+	amount, amountErr := general.ParseExtension(c.Args().Get(1))
+	if amountErr != nil {
+		_, writeErr := c.App.Writer.Write([]byte(amountErr.Error() + "\n"))
+		return writeErr
+	}
+
+	return saveOverride(c, general.Override{
+		Action: general.ExtendPeriod,
+		Period: period,
+		Amount: amount,
+	})
+}
+
+func closePeriodAction(c *cli.Context) error {
+	return periodNowAction(c, general.ClosePeriod)
+}
+
+func openPeriodAction(c *cli.Context) error {
+	return periodNowAction(c, general.OpenPeriod)
+}
+
+// periodNowAction closes or opens a period at the current time.
+func periodNowAction(c *cli.Context, action general.OverrideAction) error {
+	if allowed, err := auth.Require(c, auth.OverridePeriods); !allowed {
+		return err
+	}
+
+	settings := c.App.Metadata["settings"].(*general.AppSettings)
+
+	period, periodErr := general.ParsePeriodName(c.Args().First())
+	if periodErr != nil {
+		_, writeErr := c.App.Writer.Write([]byte(periodErr.Error() + "\n"))
+		return writeErr
+	}
+
+	var problem string
+	switch {
+	case settings.CurCycle.Skipped:
+		problem = "This week is skipped."
+	case action == general.ClosePeriod && period == general.Sleep:
+		problem = "Sleep lasts until the next week starts and can't be closed."
+	case action == general.OpenPeriod && period == general.Suggesting:
+		problem = "Suggesting opens when the week starts."
+	case action == general.ClosePeriod && period < settings.CurPeriod.Name:
+		problem = fmt.Sprintf("The %s period has already closed, extend it to open it again.", period)
+	case action == general.OpenPeriod && period <= settings.CurPeriod.Name:
+		problem = fmt.Sprintf("The %s period has already opened.", period)
+	}
+
+	if problem != "" {
+		_, writeErr := c.App.Writer.Write([]byte(problem + "\n"))
+		return writeErr
+	}
+
+	return saveOverride(c, general.Override{
+		Action: action,
+		Period: period,
+	})
+}
+
+func skipWeekAction(c *cli.Context) error {
+	if allowed, err := auth.Require(c, auth.OverridePeriods); !allowed {
+		return err
+	}
+
+	settings := c.App.Metadata["settings"].(*general.AppSettings)
+	if settings.CurCycle.Skipped {
+		_, writeErr := c.App.Writer.Write([]byte("This week is already skipped.\n"))
+		return writeErr
+	}
+
+	return saveOverride(c, general.Override{
+		Action: general.SkipWeek,
+		Period: general.Sleep,
+	})
+}
+
+// saveOverride records the override against the current week and reports the
+// period the week is in afterwards.
+func saveOverride(c *cli.Context, override general.Override) error {
+	settings := c.App.Metadata["settings"].(*general.AppSettings)
+	overrideRepository := c.App.Metadata["overrides"].(general.OverrideStore)
+
+	override.At = settings.Now
+	override.Author = c.String("user")
+
+	if err := overrideRepository.SaveOverride(c.Context, settings.WeekID, override); err != nil {
+		return err
+	}
+
+	updated, updatedErr := general.LoadAppSettings(c.Context, settings.Config, settings.Now, overrideRepository)
+	if updatedErr != nil {
+		return updatedErr
+	}
+
+	// Commands run after this one see the override straight away
+	c.App.Metadata["settings"] = updated
+
+	_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("Week %s is now in %s for %s.\n",
+		updated.WeekID,
+		updated.CurPeriod.Name,
+		general.FormatDuration(updated.CurPeriod.Remaining))))
+	return writeErr
+}
+
+func showWeekAction(c *cli.Context) error {
+	settings := c.App.Metadata["settings"].(*general.AppSettings)
+	overrideRepository := c.App.Metadata["overrides"].(general.OverrideStore)
+
+	overrides, err := overrideRepository.Overrides(c.Context, settings.WeekID)
+	if err != nil {
+		return err
+	}
+
+	cycle := settings.CurCycle
+	const timeFormat = "Mon Jan 2 15:04"
+
+	var outputBuffer strings.Builder
+
+	outputBuffer.WriteString(fmt.Sprintf("Week %s is in %s for %s.\n",
+		settings.WeekID,
+		settings.CurPeriod.Name,
+		general.FormatDuration(settings.CurPeriod.Remaining)))
+
+	if cycle.Skipped {
+		outputBuffer.WriteString("Movie night is skipped this week.\n")
+	} else {
+		outputBuffer.WriteString(fmt.Sprintf("%-20s%s\n", "Suggesting opens", cycle.Start.Format(timeFormat)))
+		outputBuffer.WriteString(fmt.Sprintf("%-20s%s\n", "Voting opens", cycle.SuggestingCloses.Format(timeFormat)))
+		outputBuffer.WriteString(fmt.Sprintf("%-20s%s\n", "Movie night starts", cycle.VotingCloses.Format(timeFormat)))
+		outputBuffer.WriteString(fmt.Sprintf("%-20s%s\n", "Movie night ends", cycle.MovieNightEnds.Format(timeFormat)))
+	}
+
+	for _, override := range overrides {
+		outputBuffer.WriteString(fmt.Sprintf("%s: %s\n",
+			override.At.In(&settings.Localization).Format(timeFormat),
+			override))
+	}
+
+	_, writeErr := c.App.Writer.Write([]byte(outputBuffer.String()))
+	return writeErr
+}
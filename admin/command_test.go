@@ -0,0 +1,43 @@
+package admin_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/movtest"
+)
+
+func TestGivenAnAdminClosesSuggestingSuggestionsCanNoLongerBeAdded(t *testing.T) {
+	h := movtest.New(t)
+	h.Config.Admins = []string{"olivia"}
+
+	closed := h.MustRun("olivia", "admin period close suggesting")
+	output := h.MustRun("liam", "suggestions add Shrek")
+
+	if !strings.Contains(closed, "is now in voting") || !strings.Contains(output, "The suggesting period has already ended") {
+		t.Fail()
+	}
+}
+
+func TestGivenASkippedWeekVotesCanNotBeCast(t *testing.T) {
+	h := movtest.New(t)
+	h.Config.Admins = []string{"olivia"}
+
+	h.MustRun("olivia", "admin week skip")
+	output := h.MustRun("liam", "votes cast 1")
+
+	if !strings.Contains(output, "skipped this week") {
+		t.Fail()
+	}
+}
+
+func TestGivenANonAdminOverridesAreRejected(t *testing.T) {
+	h := movtest.New(t)
+
+	output := h.MustRun("liam", "admin week skip")
+	shown := h.MustRun("liam", "admin week show")
+
+	if !strings.Contains(output, "need to be an admin") || strings.Contains(shown, "skipped") {
+		t.Fail()
+	}
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/urfave/cli/v2"
+)
+
+// RoleOf looks up the role of the user running the command. Users listed in
+// the admins configuration are always admins so there is someone to hand
+// out the first roles.
+func RoleOf(c *cli.Context) (Role, error) {
+	settings := c.App.Metadata["settings"].(*general.AppSettings)
+	userRepository := c.App.Metadata["users"].(Store)
+	user := c.String("user")
+
+	for _, admin := range settings.Config.Admins {
+		if admin == user {
+			return Admin, nil
+		}
+	}
+
+	return userRepository.Role(c.Context, user)
+}
+
+// Allowed reports whether the user running the command has the permission.
+func Allowed(c *cli.Context, permission Permission) (bool, error) {
+	role, err := RoleOf(c)
+	if err != nil {
+		return false, err
+	}
+
+	return role.Can(permission), nil
+}
+
+// Require is Allowed, apologising to the user when they lack the permission.
+// Actions should return the error and stop when it isn't allowed.
+func Require(c *cli.Context, permission Permission) (bool, error) {
+	allowed, err := Allowed(c, permission)
+	if err != nil || allowed {
+		return allowed, err
+	}
+
+	_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("Sorry, you need to be %s to do that.\n",
+		article(permissions[permission]))))
+	return false, writeErr
+}
+
+func article(role Role) string {
+	if role == Admin {
+		return "an " + role.String()
+	}
+
+	return "a " + role.String()
+}
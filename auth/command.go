@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	description := `List users with roles:
+    mov roles list
+
+Give a user a role:
+    mov roles set [user] [member|moderator|admin]
+
+	Moderators may edit or remove anyone's suggestions and grant exceptions to the suggestion limits. Admins may also override periods and give roles.
+`
+
+	return &cli.Command{
+		Name:        "roles",
+		Usage:       "manages user roles",
+		Description: description,
+		Subcommands: []*cli.Command{
+			{
+				Name:    "list",
+				Aliases: []string{"l"},
+				Usage:   "Lists users with roles",
+				Action:  listRolesAction,
+			},
+			{
+				Name:      "set",
+				Usage:     "Gives a user a role",
+				ArgsUsage: "<user> <role>",
+				Action:    setRoleAction,
+			},
+		},
+	}
+}
+
+func listRolesAction(c *cli.Context) error {
+	userRepository := c.App.Metadata["users"].(Store)
+
+	roles, err := userRepository.Roles(c.Context)
+	if err != nil {
+		return err
+	}
+
+	var outputBuffer strings.Builder
+
+	outputBuffer.WriteString(fmt.Sprintf("%-33.32s%s\n", "User", "Role"))
+	for _, userRole := range roles {
+		outputBuffer.WriteString(fmt.Sprintf("%-33.32s%s\n", userRole.User, userRole.Role))
+	}
+
+	_, writeErr := c.App.Writer.Write([]byte(outputBuffer.String()))
+	return writeErr
+}
+
+func setRoleAction(c *cli.Context) error {
+	if allowed, err := Require(c, ManageRoles); !allowed {
+		return err
+	}
+
+	userRepository := c.App.Metadata["users"].(Store)
+
+	if c.NArg() < 2 {
+		_, writeErr := c.App.Writer.Write([]byte("User and role not provided as arguments.\n"))
+		return writeErr
+	}
+
+	user := c.Args().Get(0)
+	role, roleErr := ParseRole(c.Args().Get(1))
+	if roleErr != nil {
+		_, writeErr := c.App.Writer.Write([]byte(roleErr.Error() + "\n"))
+		return writeErr
+	}
+
+	if err := userRepository.SetRole(c.Context, user, role); err != nil {
+		return err
+	}
+
+	_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("%s is now %s.\n", user, article(role))))
+	return writeErr
+}
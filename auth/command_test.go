@@ -0,0 +1,32 @@
+package auth_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/movtest"
+)
+
+func TestGivenAModeratorTheyCanRemoveAnotherUsersSuggestion(t *testing.T) {
+	h := movtest.New(t)
+	h.Config.Admins = []string{"olivia"}
+	h.MustRun("liam", "suggestions add Shrek")
+
+	refused := h.MustRun("noah", "suggestions remove 1")
+	granted := h.MustRun("olivia", "roles set noah moderator")
+	h.MustRun("noah", "suggestions remove 1")
+
+	if !strings.Contains(refused, "can't remove it") || !strings.Contains(granted, "noah is now a moderator") || strings.Contains(h.MustRun("noah", "suggestions list"), "Shrek") {
+		t.Fail()
+	}
+}
+
+func TestGivenAMemberTheyCanNotGiveRoles(t *testing.T) {
+	h := movtest.New(t)
+
+	output := h.MustRun("liam", "roles set liam admin")
+
+	if !strings.Contains(output, "need to be an admin") || strings.Contains(h.MustRun("liam", "roles list"), "liam") {
+		t.Fail()
+	}
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+)
+
+// Store keeps the roles of users.
+type Store interface {
+	// Role returns Member for users that aren't stored.
+	Role(ctx context.Context, user string) (Role, error)
+	SetRole(ctx context.Context, user string, role Role) error
+	// Roles lists the users with a role other than member.
+	Roles(ctx context.Context) ([]UserRole, error)
+}
+
+// Repository is the SQL Store, for SQLite and PostgreSQL.
+type Repository struct {
+	session *storage.DB
+}
+
+type UserRole struct {
+	User string
+	Role Role
+}
+
+func NewRepository(session *storage.DB) *Repository {
+	return &Repository{
+		session: session,
+	}
+}
+
+// Role returns the stored role of a user, users that aren't stored are
+// members.
+func (context *Repository) Role(ctx context.Context, user string) (Role, error) {
+	var role string
+	err := context.session.QueryRowContext(ctx, `SELECT role FROM users WHERE name = ?`, user).Scan(&role)
+	if err == sql.ErrNoRows {
+		return Member, nil
+	}
+
+	if err != nil {
+		return Member, errors.Wrap(err, "")
+	}
+
+	return ParseRole(role)
+}
+
+func (context *Repository) SetRole(ctx context.Context, user string, role Role) error {
+	_, err := context.session.ExecContext(ctx, `
+		INSERT INTO users (name, role)
+		VALUES (?, ?)
+		ON CONFLICT(name) DO UPDATE SET role = excluded.role`,
+		user, role.String())
+
+	return errors.Wrap(err, "")
+}
+
+// Roles lists the users with a role other than member.
+func (context *Repository) Roles(ctx context.Context) ([]UserRole, error) {
+	rows, err := context.session.QueryContext(ctx, `SELECT name, role FROM users WHERE role <> ? ORDER BY name`, Member.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	defer rows.Close()
+
+	var roles []UserRole
+	for rows.Next() {
+		var userRole UserRole
+		var role string
+		if err := rows.Scan(&userRole.User, &role); err != nil {
+			return nil, errors.Wrap(err, "")
+		}
+
+		if userRole.Role, err = ParseRole(role); err != nil {
+			return nil, err
+		}
+
+		roles = append(roles, userRole)
+	}
+
+	return roles, errors.Wrap(rows.Err(), "")
+}
@@ -0,0 +1,51 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/auth"
+	"github.com/fredlawl/200-colony-movie-night-bot/dbtest"
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+)
+
+func TestGivenSetRolesRolesListsEveryoneButMembers(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, session *storage.DB) {
+		repository := auth.NewRepository(session)
+		ctx := context.Background()
+
+		for user, role := range map[string]auth.Role{"olivia": auth.Admin, "noah": auth.Admin, "liam": auth.Moderator} {
+			if err := repository.SetRole(ctx, user, role); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Setting a role again replaces it
+		if err := repository.SetRole(ctx, "noah", auth.Moderator); err != nil {
+			t.Fatal(err)
+		}
+		if err := repository.SetRole(ctx, "liam", auth.Member); err != nil {
+			t.Fatal(err)
+		}
+
+		noah, err := repository.Role(ctx, "noah")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		emma, err := repository.Role(ctx, "emma")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		roles, err := repository.Roles(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if noah != auth.Moderator || emma != auth.Member || len(roles) != 2 ||
+			roles[0] != (auth.UserRole{User: "noah", Role: auth.Moderator}) || roles[1] != (auth.UserRole{User: "olivia", Role: auth.Admin}) {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Role decides what a user may do. Each role can do everything the roles
+// before it can.
+type Role int
+
+const (
+	Member Role = iota
+	Moderator
+	Admin
+)
+
+func (role Role) String() string {
+	switch role {
+	case Moderator:
+		return "moderator"
+	case Admin:
+		return "admin"
+	default:
+		return "member"
+	}
+}
+
+func ParseRole(raw string) (Role, error) {
+	for _, role := range []Role{Member, Moderator, Admin} {
+		if strings.EqualFold(raw, role.String()) {
+			return role, nil
+		}
+	}
+
+	return Member, fmt.Errorf("\"%s\" is not a role, try member, moderator or admin", raw)
+}
+
+type Permission string
+
+const (
+	// IgnorePeriods allows suggesting and voting outside of their periods,
+	// and seeing results before voting closes.
+	IgnorePeriods Permission = "ignore-periods"
+	// RemoveAnySuggestion allows removing suggestions of other users.
+	RemoveAnySuggestion Permission = "remove-any-suggestion"
+	// EditAnySuggestion allows changing the titles of other users'
+	// suggestions.
+	EditAnySuggestion Permission = "edit-any-suggestion"
+	// GrantExceptions allows letting a user suggest past the weekly limits.
+	GrantExceptions Permission = "grant-exceptions"
+	// OverridePeriods allows extending, closing, opening and skipping
+	// periods.
+	OverridePeriods Permission = "override-periods"
+	// ManageRoles allows changing the roles of users.
+	ManageRoles Permission = "manage-roles"
+	// ManageDatabase allows applying and rolling back migrations.
+	ManageDatabase Permission = "manage-database"
+	// ViewConfig allows showing the configuration in effect.
+	ViewConfig Permission = "view-config"
+)
+
+// permissions maps each permission to the least role granted it.
+var permissions = map[Permission]Role{
+	RemoveAnySuggestion: Moderator,
+	EditAnySuggestion:   Moderator,
+	GrantExceptions:     Moderator,
+	IgnorePeriods:       Admin,
+	OverridePeriods:     Admin,
+	ManageRoles:         Admin,
+	ManageDatabase:      Admin,
+	ViewConfig:          Admin,
+}
+
+// Can reports whether the role has the permission.
+func (role Role) Can(permission Permission) bool {
+	required, exists := permissions[permission]
+	return exists && role >= required
+}
@@ -0,0 +1,35 @@
+package auth
+
+import "testing"
+
+func TestGivenAModeratorTheyCanRemoveAnySuggestionButNotOverridePeriods(t *testing.T) {
+	if !Moderator.Can(RemoveAnySuggestion) || Moderator.Can(OverridePeriods) {
+		t.Fail()
+	}
+}
+
+func TestGivenAnAdminTheyHaveEveryPermission(t *testing.T) {
+	for permission := range permissions {
+		if !Admin.Can(permission) {
+			t.Errorf("expected admins to %s", permission)
+		}
+	}
+}
+
+func TestGivenAMemberTheyHaveNoPermissions(t *testing.T) {
+	for permission := range permissions {
+		if Member.Can(permission) {
+			t.Errorf("expected members not to %s", permission)
+		}
+	}
+}
+
+func TestGivenAnUnknownRoleParsingFails(t *testing.T) {
+	if _, err := ParseRole("owner"); err == nil {
+		t.Fail()
+	}
+
+	if role, err := ParseRole("Moderator"); err != nil || role != Moderator {
+		t.Fail()
+	}
+}
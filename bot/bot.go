@@ -0,0 +1,305 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/urfave/cli/v2"
+)
+
+// Discord rejects messages longer than this many characters.
+const maxMessageLength = 2000
+
+// Session is the part of a *discordgo.Session the bot relies on so the bot
+// can be driven by a fake session in tests.
+type Session interface {
+	AddHandler(handler interface{}) func()
+	ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSendReply(channelID string, content string, reference *discordgo.MessageReference, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ApplicationCommandBulkOverwrite(appID string, guildID string, commands []*discordgo.ApplicationCommand, options ...discordgo.RequestOption) ([]*discordgo.ApplicationCommand, error)
+	InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error
+	FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	Open() error
+	Close() error
+}
+
+// Executor runs the mov command line on behalf of a Discord user and returns
+// everything the command wrote.
+type Executor func(ctx context.Context, user string, args []string) (string, error)
+
+// Commands that take longer than this are abandoned.
+const commandTimeout = 30 * time.Second
+
+type Options struct {
+	// ChannelID is the channel prefixed messages are read from, empty for
+	// every channel the bot can see. Announcements are posted here.
+	ChannelID string
+	Prefix    string
+	// GuildID is the server slash commands are registered to. Commands
+	// registered without a server can take an hour to show up everywhere.
+	GuildID string
+}
+
+type Bot struct {
+	session  Session
+	execute  Executor
+	commands []*cli.Command
+	options  Options
+	private  map[string]bool
+}
+
+// NewBot creates a bot that runs the given commands from prefixed messages
+// and from the slash commands generated for them.
+func NewBot(session Session, execute Executor, commands []*cli.Command, options Options) *Bot {
+	return &Bot{
+		session:  session,
+		execute:  execute,
+		commands: commands,
+		options:  options,
+		private:  privateCommands(commands, "", map[string]bool{}),
+	}
+}
+
+// Open registers the handlers and connects to Discord. Slash commands are
+// registered once Discord says the bot is ready.
+func (b *Bot) Open() error {
+	b.session.AddHandler(func(_ *discordgo.Session, r *discordgo.Ready) {
+		if r.Application == nil {
+			return
+		}
+
+		if err := b.RegisterCommands(r.Application.ID); err != nil {
+			log.Printf("[error] unable to register slash commands %+v", err)
+		}
+	})
+
+	b.session.AddHandler(func(_ *discordgo.Session, m *discordgo.MessageCreate) {
+		b.HandleMessage(m)
+	})
+
+	b.session.AddHandler(func(_ *discordgo.Session, i *discordgo.InteractionCreate) {
+		b.HandleInteraction(i)
+	})
+
+	return b.session.Open()
+}
+
+// RegisterCommands replaces the application's slash commands with ones
+// generated from the command tree.
+func (b *Bot) RegisterCommands(appID string) error {
+	_, err := b.session.ApplicationCommandBulkOverwrite(appID, b.options.GuildID, ApplicationCommands(b.commands))
+	return err
+}
+
+func (b *Bot) Close() error {
+	return b.session.Close()
+}
+
+// HandleMessage runs the command in a message and replies with its output.
+// Messages from other bots, other channels or without the prefix are ignored.
+func (b *Bot) HandleMessage(m *discordgo.MessageCreate) {
+	if m.Author == nil || m.Author.Bot {
+		return
+	}
+
+	if b.options.ChannelID != "" && m.ChannelID != b.options.ChannelID {
+		return
+	}
+
+	prefix := b.options.Prefix
+	content := strings.TrimSpace(m.Content)
+	if content != prefix && !strings.HasPrefix(content, prefix+" ") {
+		return
+	}
+
+	output := b.runMessage(m.Author.ID, strings.TrimPrefix(content, prefix))
+
+	for _, chunk := range splitMessage(output) {
+		if _, err := b.session.ChannelMessageSendReply(m.ChannelID, chunk, m.Reference()); err != nil {
+			log.Printf("[error] unable to reply to message %s: %+v", m.ID, err)
+			return
+		}
+	}
+}
+
+// HandleInteraction runs a slash command and responds with its output.
+func (b *Bot) HandleInteraction(i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	user := i.User
+	if i.Member != nil {
+		user = i.Member.User
+	}
+
+	if user == nil {
+		return
+	}
+
+	data := i.ApplicationCommandData()
+
+	var output string
+	args, err := CommandArgs(b.commands, data)
+	if err != nil {
+		output = "Unable to read that command, " + err.Error() + "."
+	} else {
+		output = b.run(user.ID, args)
+	}
+
+	var flags discordgo.MessageFlags
+	if b.private[CommandPath(data)] {
+		flags = discordgo.MessageFlagsEphemeral
+	}
+
+	for n, chunk := range splitMessage(output) {
+		var replyErr error
+		if n == 0 {
+			replyErr = b.session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: chunk,
+					Flags:   flags,
+				},
+			})
+		} else {
+			_, replyErr = b.session.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
+				Content: chunk,
+				Flags:   flags,
+			})
+		}
+
+		if replyErr != nil {
+			log.Printf("[error] unable to respond to interaction %s: %+v", i.ID, replyErr)
+			return
+		}
+	}
+}
+
+// Announce posts a message to the bot's channel, followed by the output of a
+// command when there is any.
+func (b *Bot) Announce(message string, output string) error {
+	if b.options.ChannelID == "" {
+		return errors.New("no channel is configured for announcements")
+	}
+
+	messages := []string{message}
+	if strings.TrimSpace(output) != "" {
+		messages = append(messages, splitMessage(output)...)
+	}
+
+	for _, content := range messages {
+		if _, err := b.session.ChannelMessageSend(b.options.ChannelID, content); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *Bot) runMessage(user string, command string) string {
+	args, err := Tokenize(command)
+	if err != nil {
+		return "Unable to read that command, " + err.Error() + "."
+	}
+
+	if len(args) == 0 {
+		args = []string{"help"}
+	}
+
+	// Global flags come before the command name. Only the bot gets to set
+	// them, otherwise anyone could claim to be another user.
+	if strings.HasPrefix(args[0], "-") {
+		return "Options must follow a command, try \"" + b.options.Prefix + " help\"."
+	}
+
+	if isHiddenCommand(b.commands, args) {
+		return "Unknown command, try \"" + b.options.Prefix + " help\"."
+	}
+
+	return b.run(user, args)
+}
+
+// isHiddenCommand reports whether the args name a hidden command, or one
+// under a hidden command. Hidden commands are for the command line only.
+func isHiddenCommand(commands []*cli.Command, args []string) bool {
+	for _, arg := range args {
+		var found *cli.Command
+		for _, command := range commands {
+			if command.HasName(arg) {
+				found = command
+			}
+		}
+
+		if found == nil {
+			return false
+		}
+
+		if found.Hidden {
+			return true
+		}
+
+		commands = found.Subcommands
+	}
+
+	return false
+}
+
+func (b *Bot) run(user string, args []string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	output, err := b.execute(ctx, user, args)
+	if err != nil {
+		log.Printf("[error] %s %s: %+v", user, strings.Join(args, " "), err)
+	}
+
+	if strings.TrimSpace(output) == "" {
+		if err != nil {
+			return "Something went wrong running that command."
+		}
+
+		return "Done!"
+	}
+
+	return output
+}
+
+// splitMessage wraps output in code blocks so the tables line up, splitting
+// on line breaks to stay under Discord's message length limit.
+func splitMessage(output string) []string {
+	const fence = "```"
+	limit := maxMessageLength - 2*len(fence) - 2
+
+	var chunks []string
+	var chunk strings.Builder
+
+	flush := func() {
+		if chunk.Len() > 0 {
+			chunks = append(chunks, fence+"\n"+chunk.String()+fence)
+			chunk.Reset()
+		}
+	}
+
+	for _, line := range strings.SplitAfter(strings.TrimRight(output, "\n")+"\n", "\n") {
+		for len(line) > limit {
+			flush()
+			chunk.WriteString(line[:limit])
+			line = line[limit:]
+		}
+
+		if chunk.Len()+len(line) > limit {
+			flush()
+		}
+
+		chunk.WriteString(line)
+	}
+
+	flush()
+
+	return chunks
+}
@@ -0,0 +1,214 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+type fakeReply struct {
+	channelID string
+	content   string
+	reference *discordgo.MessageReference
+}
+
+type fakeSession struct {
+	handlers   []interface{}
+	replies    []fakeReply
+	sent       []fakeReply
+	responses  []*discordgo.InteractionResponse
+	followups  []*discordgo.WebhookParams
+	registered []*discordgo.ApplicationCommand
+	opened     bool
+}
+
+func (s *fakeSession) AddHandler(handler interface{}) func() {
+	s.handlers = append(s.handlers, handler)
+	return func() {}
+}
+
+func (s *fakeSession) ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.sent = append(s.sent, fakeReply{channelID, content, nil})
+	return &discordgo.Message{ChannelID: channelID, Content: content}, nil
+}
+
+func (s *fakeSession) ChannelMessageSendReply(channelID string, content string, reference *discordgo.MessageReference, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.replies = append(s.replies, fakeReply{channelID, content, reference})
+	return &discordgo.Message{ChannelID: channelID, Content: content}, nil
+}
+
+func (s *fakeSession) ApplicationCommandBulkOverwrite(appID string, guildID string, commands []*discordgo.ApplicationCommand, options ...discordgo.RequestOption) ([]*discordgo.ApplicationCommand, error) {
+	s.registered = commands
+	return commands, nil
+}
+
+func (s *fakeSession) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error {
+	s.responses = append(s.responses, resp)
+	return nil
+}
+
+func (s *fakeSession) FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.followups = append(s.followups, data)
+	return &discordgo.Message{Content: data.Content}, nil
+}
+
+func (s *fakeSession) Open() error {
+	s.opened = true
+	return nil
+}
+
+func (s *fakeSession) Close() error {
+	s.opened = false
+	return nil
+}
+
+type executorCall struct {
+	user string
+	args []string
+}
+
+func newTestBot(output string, err error) (*Bot, *fakeSession, *[]executorCall) {
+	session := &fakeSession{}
+	calls := &[]executorCall{}
+	execute := func(ctx context.Context, user string, args []string) (string, error) {
+		*calls = append(*calls, executorCall{user, args})
+		return output, err
+	}
+
+	return NewBot(session, execute, testCommands(), Options{
+		ChannelID: "movie-night",
+		Prefix:    "!mov",
+	}), session, calls
+}
+
+func message(channelID string, authorID string, content string) *discordgo.MessageCreate {
+	return &discordgo.MessageCreate{
+		Message: &discordgo.Message{
+			ID:        "1",
+			ChannelID: channelID,
+			Content:   content,
+			Author:    &discordgo.User{ID: authorID},
+		},
+	}
+}
+
+func TestGivenAPrefixedMessageCommandRunsAsTheAuthor(t *testing.T) {
+	movieBot, session, calls := newTestBot("ID  Movie\n", nil)
+
+	movieBot.HandleMessage(message("movie-night", "1234", `!mov suggestions add "Winnie the Pooh"`))
+
+	if len(*calls) != 1 || (*calls)[0].user != "1234" {
+		t.FailNow()
+	}
+
+	args := (*calls)[0].args
+	if len(args) != 3 || args[0] != "suggestions" || args[1] != "add" || args[2] != "Winnie the Pooh" {
+		t.Fail()
+	}
+
+	if len(session.replies) != 1 || session.replies[0].content != "```\nID  Movie\n```" {
+		t.Fail()
+	}
+
+	if session.replies[0].reference == nil || session.replies[0].reference.MessageID != "1" {
+		t.Fail()
+	}
+}
+
+func TestGivenMessagesNotForTheBotTheyAreIgnored(t *testing.T) {
+	movieBot, session, calls := newTestBot("output", nil)
+
+	movieBot.HandleMessage(message("general", "1234", "!mov suggestions list"))
+	movieBot.HandleMessage(message("movie-night", "1234", "suggestions list"))
+	movieBot.HandleMessage(message("movie-night", "1234", "!movie suggestions list"))
+
+	fromBot := message("movie-night", "5678", "!mov suggestions list")
+	fromBot.Author.Bot = true
+	movieBot.HandleMessage(fromBot)
+
+	if len(*calls) != 0 || len(session.replies) != 0 {
+		t.Fail()
+	}
+}
+
+func TestGivenAGlobalFlagTheCommandIsRejected(t *testing.T) {
+	movieBot, session, calls := newTestBot("output", nil)
+
+	movieBot.HandleMessage(message("movie-night", "1234", "!mov --user 5678 votes cast 1"))
+
+	if len(*calls) != 0 || len(session.replies) != 1 {
+		t.Fail()
+	}
+}
+
+func TestGivenAFailingCommandWithoutOutputAGenericReplyIsSent(t *testing.T) {
+	movieBot, session, _ := newTestBot("", errors.New("database is locked"))
+
+	movieBot.HandleMessage(message("movie-night", "1234", "!mov votes cast 1"))
+
+	if len(session.replies) != 1 || !strings.Contains(session.replies[0].content, "Something went wrong") {
+		t.Fail()
+	}
+}
+
+func TestGivenLongOutputTheReplyIsSplit(t *testing.T) {
+	movieBot, session, _ := newTestBot(strings.Repeat(strings.Repeat("x", 99)+"\n", 50), nil)
+
+	movieBot.HandleMessage(message("movie-night", "1234", "!mov suggestions list"))
+
+	if len(session.replies) != 3 {
+		t.FailNow()
+	}
+
+	for _, reply := range session.replies {
+		if len(reply.content) > maxMessageLength {
+			t.Fail()
+		}
+	}
+}
+
+func TestGivenOpenTheHandlersAreRegistered(t *testing.T) {
+	movieBot, session, _ := newTestBot("", nil)
+
+	if err := movieBot.Open(); err != nil || !session.opened || len(session.handlers) != 3 {
+		t.Fail()
+	}
+}
+
+func TestGivenAnAnnouncementItIsPostedToTheChannelWithTheOutput(t *testing.T) {
+	movieBot, session, _ := newTestBot("", nil)
+
+	if err := movieBot.Announce("Voting is open!", "ID  Movie\n1   Shrek\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(session.sent) != 2 || session.sent[0].content != "Voting is open!" || session.sent[1].channelID != "movie-night" {
+		t.Fail()
+	}
+
+	if !strings.Contains(session.sent[1].content, "1   Shrek") {
+		t.Fail()
+	}
+}
+
+func TestGivenNoChannelAnnouncingFails(t *testing.T) {
+	session := &fakeSession{}
+	movieBot := NewBot(session, nil, testCommands(), Options{Prefix: "!mov"})
+
+	if err := movieBot.Announce("Voting is open!", ""); err == nil || len(session.sent) != 0 {
+		t.Fail()
+	}
+}
+
+func TestGivenAHiddenCommandAMessageCanNotRunIt(t *testing.T) {
+	movieBot, session, calls := newTestBot("", nil)
+
+	movieBot.HandleMessage(message("movie-night", "1234", "!mov admin rollback"))
+
+	if len(*calls) != 0 || len(session.replies) != 1 || !strings.Contains(session.replies[0].content, "Unknown command") {
+		t.Fail()
+	}
+}
@@ -0,0 +1,357 @@
+package bot
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/urfave/cli/v2"
+)
+
+// Discord limits option descriptions to this many characters.
+const maxDescriptionLength = 100
+
+// PrivateCategory is the cli.Command Category that answers the command and
+// its subcommands with messages only the user running them can see. The
+// command line lists these commands under it in help.
+const PrivateCategory = "private"
+
+// argument is a positional argument described by a command's ArgsUsage. Each
+// word of the usage becomes an argument: <name> is required, [name] is
+// optional and a trailing ... accepts several values.
+type argument struct {
+	name     string
+	required bool
+	variadic bool
+}
+
+func parseArgsUsage(usage string) []argument {
+	var arguments []argument
+	for _, word := range strings.Fields(usage) {
+		arg := argument{}
+		arg.variadic = strings.HasSuffix(word, "...")
+		word = strings.TrimSuffix(word, "...")
+		arg.required = strings.HasPrefix(word, "<")
+		arg.name = optionName(strings.Trim(word, "<>[]"))
+
+		if arg.name != "" {
+			arguments = append(arguments, arg)
+		}
+	}
+
+	return arguments
+}
+
+// ApplicationCommands turns the mov command tree into Discord slash commands.
+// Subcommands become slash subcommands, flags and positional arguments
+// become options, so new CLI commands show up in Discord on their own.
+func ApplicationCommands(commands []*cli.Command) []*discordgo.ApplicationCommand {
+	var appCommands []*discordgo.ApplicationCommand
+	for _, command := range visibleCommands(commands) {
+		appCommands = append(appCommands, &discordgo.ApplicationCommand{
+			Name:        command.Name,
+			Description: description(command.Usage, command.Name),
+			Options:     commandOptions(command, 1),
+		})
+	}
+
+	return appCommands
+}
+
+// commandOptions maps a command's subcommands or its own flags and arguments
+// to options. Discord only allows two levels of nested subcommands.
+func commandOptions(command *cli.Command, depth int) []*discordgo.ApplicationCommandOption {
+	subcommands := visibleCommands(command.Subcommands)
+	if len(subcommands) == 0 || depth > 2 {
+		return inputOptions(command)
+	}
+
+	optionType := discordgo.ApplicationCommandOptionSubCommand
+	if depth == 1 && hasSubcommands(subcommands) {
+		optionType = discordgo.ApplicationCommandOptionSubCommandGroup
+	}
+
+	var options []*discordgo.ApplicationCommandOption
+	for _, sub := range subcommands {
+		option := &discordgo.ApplicationCommandOption{
+			Type:        optionType,
+			Name:        sub.Name,
+			Description: description(sub.Usage, sub.Name),
+		}
+
+		if optionType == discordgo.ApplicationCommandOptionSubCommandGroup && len(visibleCommands(sub.Subcommands)) == 0 {
+			// Groups can only hold subcommands, so a leaf next to groups is
+			// wrapped in a subcommand of the same name.
+			option.Options = []*discordgo.ApplicationCommandOption{{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        sub.Name,
+				Description: description(sub.Usage, sub.Name),
+				Options:     inputOptions(sub),
+			}}
+		} else {
+			option.Options = commandOptions(sub, depth+1)
+		}
+
+		options = append(options, option)
+	}
+
+	return options
+}
+
+// inputOptions maps flags and positional arguments to options. Discord wants
+// required options first.
+func inputOptions(command *cli.Command) []*discordgo.ApplicationCommandOption {
+	var required []*discordgo.ApplicationCommandOption
+	var optional []*discordgo.ApplicationCommandOption
+
+	for _, arg := range parseArgsUsage(command.ArgsUsage) {
+		usage := arg.name
+		if arg.variadic {
+			usage += ", separate several with spaces"
+		}
+
+		option := &discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        arg.name,
+			Description: description(usage, arg.name),
+			Required:    arg.required,
+		}
+
+		if arg.required {
+			required = append(required, option)
+		} else {
+			optional = append(optional, option)
+		}
+	}
+
+	for _, flag := range command.Flags {
+		option := flagOption(flag)
+		if option == nil {
+			continue
+		}
+
+		if option.Required {
+			required = append(required, option)
+		} else {
+			optional = append(optional, option)
+		}
+	}
+
+	return append(required, optional...)
+}
+
+func flagOption(flag cli.Flag) *discordgo.ApplicationCommandOption {
+	if isHidden(flag) {
+		return nil
+	}
+
+	option := &discordgo.ApplicationCommandOption{
+		Type: discordgo.ApplicationCommandOptionString,
+		Name: optionName(flag.Names()[0]),
+	}
+
+	switch flag.(type) {
+	case *cli.BoolFlag:
+		option.Type = discordgo.ApplicationCommandOptionBoolean
+	case *cli.IntFlag, *cli.Int64Flag, *cli.UintFlag, *cli.Uint64Flag:
+		option.Type = discordgo.ApplicationCommandOptionInteger
+	case *cli.Float64Flag:
+		option.Type = discordgo.ApplicationCommandOptionNumber
+	}
+
+	usage := ""
+	if doc, ok := flag.(cli.DocGenerationFlag); ok {
+		usage = doc.GetUsage()
+	}
+	option.Description = description(usage, option.Name)
+
+	if required, ok := flag.(cli.RequiredFlag); ok {
+		option.Required = required.IsRequired()
+	}
+
+	return option
+}
+
+// CommandArgs turns a slash command interaction back into mov arguments. Flags
+// are placed before positional arguments since flag parsing stops at the
+// first argument.
+func CommandArgs(commands []*cli.Command, data discordgo.ApplicationCommandInteractionData) ([]string, error) {
+	command := findCommand(commands, data.Name)
+	if command == nil {
+		return nil, fmt.Errorf("unknown command \"%s\"", data.Name)
+	}
+
+	args := []string{command.Name}
+	options := data.Options
+
+	for len(options) == 1 && isSubcommand(options[0].Type) {
+		sub := findCommand(command.Subcommands, options[0].Name)
+		if sub == nil {
+			// A leaf wrapped to sit beside subcommand groups
+			if options[0].Name != command.Name {
+				return nil, fmt.Errorf("unknown command \"%s\"", options[0].Name)
+			}
+		} else {
+			command = sub
+			args = append(args, command.Name)
+		}
+
+		options = options[0].Options
+	}
+
+	var positional []string
+	for _, arg := range parseArgsUsage(command.ArgsUsage) {
+		option := findOption(options, arg.name)
+		if option == nil {
+			continue
+		}
+
+		value := fmt.Sprint(option.Value)
+		if !arg.variadic {
+			positional = append(positional, value)
+			continue
+		}
+
+		values, err := Tokenize(value)
+		if err != nil {
+			return nil, err
+		}
+
+		positional = append(positional, values...)
+	}
+
+	for _, flag := range command.Flags {
+		name := flag.Names()[0]
+		option := findOption(options, optionName(name))
+		if option == nil {
+			continue
+		}
+
+		args = append(args, fmt.Sprintf("--%s=%s", name, optionValue(option)))
+	}
+
+	if len(positional) > 0 {
+		args = append(args, "--")
+		args = append(args, positional...)
+	}
+
+	return args, nil
+}
+
+// CommandPath is the space separated name of the command an interaction runs,
+// such as "votes cast".
+func CommandPath(data discordgo.ApplicationCommandInteractionData) string {
+	path := []string{data.Name}
+	options := data.Options
+	for len(options) == 1 && isSubcommand(options[0].Type) {
+		if options[0].Name != path[len(path)-1] {
+			path = append(path, options[0].Name)
+		}
+		options = options[0].Options
+	}
+
+	return strings.Join(path, " ")
+}
+
+func optionValue(option *discordgo.ApplicationCommandInteractionDataOption) string {
+	switch value := option.Value.(type) {
+	case float64:
+		if option.Type == discordgo.ApplicationCommandOptionInteger {
+			return strconv.FormatInt(int64(value), 10)
+		}
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(option.Value)
+}
+
+func findCommand(commands []*cli.Command, name string) *cli.Command {
+	for _, command := range commands {
+		if command.Name == name {
+			return command
+		}
+	}
+
+	return nil
+}
+
+func findOption(options []*discordgo.ApplicationCommandInteractionDataOption, name string) *discordgo.ApplicationCommandInteractionDataOption {
+	for _, option := range options {
+		if option.Name == name {
+			return option
+		}
+	}
+
+	return nil
+}
+
+func visibleCommands(commands []*cli.Command) []*cli.Command {
+	var visible []*cli.Command
+	for _, command := range commands {
+		if !command.Hidden && command.Name != "help" {
+			visible = append(visible, command)
+		}
+	}
+
+	return visible
+}
+
+func hasSubcommands(commands []*cli.Command) bool {
+	for _, command := range commands {
+		if len(visibleCommands(command.Subcommands)) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isSubcommand(optionType discordgo.ApplicationCommandOptionType) bool {
+	return optionType == discordgo.ApplicationCommandOptionSubCommand ||
+		optionType == discordgo.ApplicationCommandOptionSubCommandGroup
+}
+
+// isHidden checks a flag's Hidden field the same way cli does when it
+// prints help, since the flag interface doesn't expose it.
+func isHidden(flag cli.Flag) bool {
+	value := reflect.Indirect(reflect.ValueOf(flag))
+	if value.Kind() != reflect.Struct {
+		return false
+	}
+
+	hidden := value.FieldByName("Hidden")
+	return hidden.IsValid() && hidden.Kind() == reflect.Bool && hidden.Bool()
+}
+
+func optionName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", "-"))
+}
+
+func description(usage string, fallback string) string {
+	if usage == "" {
+		usage = fallback
+	}
+
+	if runes := []rune(usage); len(runes) > maxDescriptionLength {
+		usage = string(runes[:maxDescriptionLength-3]) + "..."
+	}
+
+	return usage
+}
+
+// privateCommands collects the paths, such as "votes cast", of the commands
+// marked private.
+func privateCommands(commands []*cli.Command, parent string, private map[string]bool) map[string]bool {
+	for _, command := range visibleCommands(commands) {
+		path := strings.TrimSpace(parent + " " + command.Name)
+		if command.Category == PrivateCategory || private[parent] {
+			private[path] = true
+		}
+
+		privateCommands(command.Subcommands, path, private)
+	}
+
+	return private
+}
@@ -0,0 +1,230 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/urfave/cli/v2"
+)
+
+func testCommands() []*cli.Command {
+	noop := func(c *cli.Context) error { return nil }
+
+	return []*cli.Command{
+		{
+			Name:  "suggestions",
+			Usage: "manages movie suggestions",
+			Subcommands: []*cli.Command{
+				{Name: "list", Usage: "Lists suggested movies", Action: noop},
+				{Name: "add", Usage: "Suggest a movie", ArgsUsage: "<movie>", Action: noop},
+			},
+		},
+		{
+			Name:  "votes",
+			Usage: "manages movie votes",
+			Subcommands: []*cli.Command{
+				{Name: "cast", Usage: "Casts votes", ArgsUsage: "<ids>...", Action: noop, Category: PrivateCategory},
+				{
+					Name:  "results",
+					Usage: "Tallies the votes",
+					Flags: []cli.Flag{
+						&cli.StringFlag{Name: "method", Aliases: []string{"m"}, Usage: "counting method"},
+						&cli.BoolFlag{Name: "bypass", Hidden: true},
+					},
+					Action: noop,
+				},
+			},
+		},
+		{
+			Name:  "admin",
+			Usage: "administration",
+			Subcommands: []*cli.Command{
+				{
+					Name: "period",
+					Subcommands: []*cli.Command{
+						{Name: "close", ArgsUsage: "<period>", Action: noop},
+					},
+				},
+				{Name: "skip", Action: noop},
+				{Name: "rollback", Hidden: true, Action: noop},
+			},
+		},
+	}
+}
+
+func subcommand(name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{
+		Name:    name,
+		Type:    discordgo.ApplicationCommandOptionSubCommand,
+		Options: options,
+	}
+}
+
+func group(name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	option := subcommand(name, options...)
+	option.Type = discordgo.ApplicationCommandOptionSubCommandGroup
+	return option
+}
+
+func value(name string, optionType discordgo.ApplicationCommandOptionType, v interface{}) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: optionType, Value: v}
+}
+
+func TestGivenTheCommandTreeSlashCommandsMirrorIt(t *testing.T) {
+	actual := ApplicationCommands(testCommands())
+
+	if len(actual) != 3 || actual[0].Name != "suggestions" || len(actual[0].Options) != 2 {
+		t.FailNow()
+	}
+
+	add := actual[0].Options[1]
+	if add.Type != discordgo.ApplicationCommandOptionSubCommand || len(add.Options) != 1 {
+		t.FailNow()
+	}
+
+	if add.Options[0].Name != "movie" || !add.Options[0].Required || add.Options[0].Type != discordgo.ApplicationCommandOptionString {
+		t.Fail()
+	}
+
+	results := actual[1].Options[1]
+	if len(results.Options) != 1 || results.Options[0].Name != "method" || results.Options[0].Description != "counting method" {
+		t.Fail()
+	}
+}
+
+func TestGivenNestedCommandsSlashCommandGroupsAreUsed(t *testing.T) {
+	admin := ApplicationCommands(testCommands())[2]
+
+	period := admin.Options[0]
+	if period.Type != discordgo.ApplicationCommandOptionSubCommandGroup || period.Options[0].Name != "close" {
+		t.Fail()
+	}
+
+	skip := admin.Options[1]
+	if skip.Type != discordgo.ApplicationCommandOptionSubCommandGroup || len(skip.Options) != 1 ||
+		skip.Options[0].Type != discordgo.ApplicationCommandOptionSubCommand || skip.Options[0].Name != "skip" {
+		t.Fail()
+	}
+}
+
+func TestGivenAnInteractionCommandArgsPutsFlagsBeforeArguments(t *testing.T) {
+	data := discordgo.ApplicationCommandInteractionData{
+		Name: "votes",
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			subcommand("results", value("method", discordgo.ApplicationCommandOptionString, "borda")),
+		},
+	}
+
+	actual, err := CommandArgs(testCommands(), data)
+
+	expected := []string{"votes", "results", "--method=borda"}
+	if err != nil || !equalArgs(expected, actual) {
+		t.Fail()
+	}
+}
+
+func TestGivenAVariadicArgumentCommandArgsSplitsIt(t *testing.T) {
+	data := discordgo.ApplicationCommandInteractionData{
+		Name: "votes",
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			subcommand("cast", value("ids", discordgo.ApplicationCommandOptionString, "3 1 2")),
+		},
+	}
+
+	actual, err := CommandArgs(testCommands(), data)
+
+	expected := []string{"votes", "cast", "--", "3", "1", "2"}
+	if err != nil || !equalArgs(expected, actual) {
+		t.Fail()
+	}
+}
+
+func TestGivenAWrappedLeafCommandArgsAndPathSkipTheWrapper(t *testing.T) {
+	data := discordgo.ApplicationCommandInteractionData{
+		Name: "admin",
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			group("skip", subcommand("skip")),
+		},
+	}
+
+	actual, err := CommandArgs(testCommands(), data)
+
+	if err != nil || !equalArgs([]string{"admin", "skip"}, actual) || CommandPath(data) != "admin skip" {
+		t.Fail()
+	}
+}
+
+func interaction(userID string, data discordgo.ApplicationCommandInteractionData) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:     "1",
+			Type:   discordgo.InteractionApplicationCommand,
+			Data:   data,
+			Member: &discordgo.Member{User: &discordgo.User{ID: userID}},
+		},
+	}
+}
+
+func TestGivenAPrivateSlashCommandTheResponseIsEphemeral(t *testing.T) {
+	movieBot, session, calls := newTestBot("", nil)
+
+	movieBot.HandleInteraction(interaction("1234", discordgo.ApplicationCommandInteractionData{
+		Name: "votes",
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			subcommand("cast", value("ids", discordgo.ApplicationCommandOptionString, "1")),
+		},
+	}))
+
+	if len(*calls) != 1 || (*calls)[0].user != "1234" {
+		t.FailNow()
+	}
+
+	if len(session.responses) != 1 || session.responses[0].Data.Flags != discordgo.MessageFlagsEphemeral {
+		t.Fail()
+	}
+}
+
+func TestGivenAPublicSlashCommandTheResponseIsVisible(t *testing.T) {
+	movieBot, session, _ := newTestBot("ID  Movie\n", nil)
+
+	movieBot.HandleInteraction(interaction("1234", discordgo.ApplicationCommandInteractionData{
+		Name: "suggestions",
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			subcommand("list"),
+		},
+	}))
+
+	if len(session.responses) != 1 || session.responses[0].Data.Flags != 0 ||
+		session.responses[0].Data.Content != "```\nID  Movie\n```" {
+		t.Fail()
+	}
+}
+
+func TestGivenALongMultiByteUsageTheDescriptionIsCutOnACharacter(t *testing.T) {
+	actual := description(strings.Repeat("é", maxDescriptionLength+1), "")
+
+	if !utf8.ValidString(actual) || utf8.RuneCountInString(actual) != maxDescriptionLength || !strings.HasSuffix(actual, "...") {
+		t.Fail()
+	}
+}
+
+func TestGivenAPrivateCommandItsSubcommandsArePrivate(t *testing.T) {
+	commands := []*cli.Command{
+		{
+			Name:     "config",
+			Category: PrivateCategory,
+			Subcommands: []*cli.Command{
+				{Name: "show"},
+			},
+		},
+		{Name: "suggestions", Subcommands: []*cli.Command{{Name: "list"}}},
+	}
+
+	private := privateCommands(commands, "", map[string]bool{})
+
+	if !private["config"] || !private["config show"] || private["suggestions list"] {
+		t.Fail()
+	}
+}
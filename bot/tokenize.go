@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// Tokenize splits a message into arguments the way a shell would. Words are
+// separated by whitespace and can be grouped with single or double quotes,
+// including the curly quotes phones like to insert. A backslash escapes the
+// next character. Quotes only open at the start of a word so titles like
+// Ocean's Eleven don't need quoting.
+func Tokenize(s string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	var quote rune
+	inArg := false
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if closesQuote(quote, r) {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case isQuote(r) && !inArg:
+			quote = r
+			inArg = true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.New("missing a closing quote")
+	}
+
+	if escaped {
+		arg.WriteRune('\\')
+	}
+
+	if inArg {
+		args = append(args, arg.String())
+	}
+
+	return args, nil
+}
+
+func isQuote(r rune) bool {
+	return r == '"' || r == '\'' || r == '“' || r == '‘'
+}
+
+func closesQuote(open rune, r rune) bool {
+	switch open {
+	case '“':
+		return r == '”'
+	case '‘':
+		return r == '’'
+	}
+
+	return r == open
+}
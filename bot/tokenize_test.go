@@ -0,0 +1,52 @@
+package bot
+
+import (
+	"testing"
+)
+
+func equalArgs(expected []string, actual []string) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+
+	for i := range expected {
+		if expected[i] != actual[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestGivenQuotedWordsTokenizeKeepsThemTogether(t *testing.T) {
+	actual, err := Tokenize(`  suggestions add "Winnie the Pooh"  'Shrek 2' “The Thing” `)
+
+	expected := []string{"suggestions", "add", "Winnie the Pooh", "Shrek 2", "The Thing"}
+	if err != nil || !equalArgs(expected, actual) {
+		t.Fail()
+	}
+}
+
+func TestGivenAnApostropheInAWordTokenizeDoesNotStartAQuote(t *testing.T) {
+	actual, err := Tokenize(`suggestions add Ocean's Eleven`)
+
+	expected := []string{"suggestions", "add", "Ocean's", "Eleven"}
+	if err != nil || !equalArgs(expected, actual) {
+		t.Fail()
+	}
+}
+
+func TestGivenEscapedCharactersTokenizeKeepsThemLiteral(t *testing.T) {
+	actual, err := Tokenize(`add "say \"hi\"" a\ b`)
+
+	expected := []string{"add", `say "hi"`, "a b"}
+	if err != nil || !equalArgs(expected, actual) {
+		t.Fail()
+	}
+}
+
+func TestGivenAnUnclosedQuoteTokenizeErrors(t *testing.T) {
+	if _, err := Tokenize(`add "Shrek`); err == nil {
+		t.Fail()
+	}
+}
@@ -1,9 +1,7 @@
 package main
 
-import "fmt"
-
-// TODO: Use https://pkg.go.dev/github.com/bwmarrin/discordgo for the discord integration
+import "github.com/fredlawl/200-colony-movie-night-bot/runner"
 
 func main() {
-	fmt.Println("Hello, Bot!")
+	runner.RunBot()
 }
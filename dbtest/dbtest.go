@@ -0,0 +1,106 @@
+// Package dbtest opens migrated SQL databases for repository tests. SQLite
+// runs in memory. PostgreSQL runs when MOV_TEST_POSTGRES holds a connection
+// string, such as "postgres://mov@localhost/mov_test?sslmode=disable", and
+// is skipped otherwise.
+package dbtest
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/migrations"
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+)
+
+// PostgresEnv names the environment variable with the PostgreSQL connection
+// string.
+const PostgresEnv = "MOV_TEST_POSTGRES"
+
+var schemas int64
+
+// Each runs the test against every driver available, as a subtest named
+// after the driver.
+func Each(t *testing.T, test func(t *testing.T, session *storage.DB)) {
+	for _, driver := range []string{storage.SQLite, storage.Postgres} {
+		driver := driver
+		t.Run(driver, func(t *testing.T) {
+			test(t, Open(t, driver))
+		})
+	}
+}
+
+// Open returns a migrated database of the driver, closed when the test ends.
+func Open(t *testing.T, driver string) *storage.DB {
+	session := Empty(t, driver)
+
+	migrator, err := migrations.NewMigrator(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := migrator.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	return session
+}
+
+// Empty returns a database of the driver without any migrations applied.
+// Each PostgreSQL database is a schema of its own, dropped when the test
+// ends, so tests of several packages can share the server.
+func Empty(t *testing.T, driver string) *storage.DB {
+	if driver == storage.SQLite {
+		session, err := storage.Open(storage.SQLite, ":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Every connection to :memory: is a new database
+		session.SetMaxOpenConns(1)
+		t.Cleanup(func() { session.Close() })
+
+		return session
+	}
+
+	source := os.Getenv(PostgresEnv)
+	if source == "" {
+		t.Skipf("%s is not set", PostgresEnv)
+	}
+
+	admin, err := storage.Open(storage.Postgres, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("mov_test_%d_%d", os.Getpid(), atomic.AddInt64(&schemas, 1))
+	if _, err := admin.Exec(`CREATE SCHEMA ` + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`) })
+
+	session, err := storage.Open(storage.Postgres, withSearchPath(source, schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { session.Close() })
+
+	return session
+}
+
+// withSearchPath adds the search_path run-time parameter to a URL or
+// key=value connection string.
+func withSearchPath(source string, schema string) string {
+	if !strings.Contains(source, "://") {
+		return source + " search_path=" + schema
+	}
+
+	if strings.Contains(source, "?") {
+		return source + "&search_path=" + schema
+	}
+
+	return source + "?search_path=" + schema
+}
@@ -1,6 +1,8 @@
 package general
 
 import (
+	"context"
+	"fmt"
 	"time"
 )
 
@@ -13,92 +15,156 @@ const (
 	Sleep
 )
 
-// Configuration is based on a single 7 day week, with the seggestion
-// date starting on Monday.
+// Configuration is based on a single 7 day cycle. Suggesting opens at the
+// cycle start, and each period closes at a weekday and time of day such as
+// "Thursday 18:00". Sleep lasts until the next cycle starts.
+//
+// Each field can be set from the config file, a MOV_* environment variable
+// or a global flag, all named after the config tag. See LoadConfig.
 type AppConfig struct {
-	Localization           string
-	SuggestionPeriodInDays int
-	VotePeriodInDays       int
-	MovieNightPeriodInDays int
-	DbFilePath             string
+	Localization        string   `config:"timezone" usage:"time zone periods are calculated in"`
+	CycleStart          string   `config:"cycle-start" usage:"weekday and time suggesting opens, such as \"Monday 00:00\""`
+	SuggestingCloses    string   `config:"suggesting-closes" usage:"weekday and time suggesting closes and voting opens"`
+	VotingCloses        string   `config:"voting-closes" usage:"weekday and time voting closes and movie night starts"`
+	MovieNightEnds      string   `config:"movie-night-ends" usage:"weekday and time movie night ends"`
+	DbDriver            string   `config:"db-driver" usage:"database driver: sqlite3 or postgres"`
+	DbFilePath          string   `config:"db" usage:"SQLite database path or PostgreSQL connection string" secret:"true"`
+	AutoMigrate         bool     `config:"auto-migrate" usage:"apply pending database migrations at startup"`
+	TallyMethod         string   `config:"tally-method" usage:"vote counting method: irv, borda, schulze or plurality"`
+	TieBreak            string   `config:"tally-tie-break" usage:"tie-break rule: earliest, fewest-wins or random"`
+	RewatchCooldown     int      `config:"rewatch-cooldown" usage:"months before a watched movie can be suggested again, 0 to only warn"`
+	CarryOver           int      `config:"carry-over" usage:"runners-up copied into the next week when it starts, -1 for all, 0 for none"`
+	SuggestionLimit     int      `config:"suggestion-limit" usage:"suggestions each user may add a week, 0 for no limit"`
+	BallotLimit         int      `config:"ballot-limit" usage:"suggestions the ballot may hold, 0 for no limit"`
+	NominationThreshold int      `config:"nomination-threshold" usage:"seconds a suggestion needs to make the ballot, 0 for none"`
+	DiscordToken        string   `config:"discord-token" usage:"Discord bot token" secret:"true"`
+	DiscordChannelID    string   `config:"discord-channel" usage:"channel the bot listens in, empty for all channels"`
+	DiscordGuildID      string   `config:"discord-guild" usage:"server slash commands are registered to, empty for all servers"`
+	CommandPrefix       string   `config:"command-prefix" usage:"prefix of messages the bot answers"`
+	Admins              []string `config:"admins" usage:"comma separated users that are always admins"`
 }
 
 type Period struct {
-	Name     PeriodName
-	DaysLeft int
+	Name      PeriodName
+	Remaining time.Duration // Time left until the period ends.
 }
 
 type AppSettings struct {
 	Config       AppConfig
+	Sources      ConfigSources
 	CurPeriod    Period
+	CurCycle     Cycle
 	Localization time.Location
 	WeekID       WeekID
 	CurDay       time.Time // This is the current day with no time.
+	Now          time.Time // The time the settings were established at.
 	AppID        string
 }
 
 func DefaultConfiguration() AppConfig {
 	return AppConfig{
-		Localization:           "America/Chicago",
-		SuggestionPeriodInDays: 3,
-		VotePeriodInDays:       1,
-		MovieNightPeriodInDays: 1,
-		DbFilePath:             "./sqlite-cli.db",
+		Localization:     "America/Chicago",
+		CycleStart:       "Monday 00:00",
+		SuggestingCloses: "Thursday 00:00",
+		VotingCloses:     "Friday 00:00",
+		MovieNightEnds:   "Saturday 00:00",
+		DbDriver:         "sqlite3",
+		DbFilePath:       "./sqlite-cli.db",
+		AutoMigrate:      true,
+		TallyMethod:      "irv",
+		TieBreak:         "earliest",
+		RewatchCooldown:  12,
+		CarryOver:        3,
+		SuggestionLimit:  3,
+		BallotLimit:      20,
+		CommandPrefix:    "!mov",
 	}
 }
 
-func CreateAppSettings(cfg AppConfig) (*AppSettings, error) {
+// LoadAppSettings establishes the week and period as of the given time with
+// the week's admin overrides applied. The store may be nil.
+func LoadAppSettings(ctx context.Context, cfg AppConfig, now time.Time, overrides OverrideStore) (*AppSettings, error) {
 	loc, locErr := time.LoadLocation(cfg.Localization)
 
 	if locErr != nil {
 		return nil, locErr
 	}
 
+	sched, schedErr := parseSchedule(cfg)
+	if schedErr != nil {
+		return nil, schedErr
+	}
+
 	settings := AppSettings{
 		Config:       cfg,
 		Localization: *loc,
 	}
 
-	settings.setTime(time.Now())
+	if err := settings.setTime(ctx, sched, now, overrides); err != nil {
+		return nil, err
+	}
 
 	return &settings, nil
 }
 
-// Reconfigure settings to a new time. This is especially useful for testing
-// purposes.
-func (settings *AppSettings) setTime(now time.Time) {
-	now = now.In(&settings.Localization)
-	settings.CurDay = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0,
-		0, &settings.Localization)
-	settings.WeekID = WeekIDFromTime(now)
-	settings.CurPeriod = calculatePeriod(settings.Config, settings.CurDay)
-}
+// WeekSettings establishes the settings as of the start of the week's cycle,
+// to look back on a week other than the current one.
+func WeekSettings(ctx context.Context, cfg AppConfig, week WeekID, overrides OverrideStore) (*AppSettings, error) {
+	loc, locErr := time.LoadLocation(cfg.Localization)
+	if locErr != nil {
+		return nil, locErr
+	}
 
-func calculatePeriod(cfg AppConfig, now time.Time) Period {
-	curPeriod := Period{
-		Name:     Sleep,
-		DaysLeft: 0,
+	sched, schedErr := parseSchedule(cfg)
+	if schedErr != nil {
+		return nil, schedErr
 	}
 
-	suggestionPeriodStart := now.AddDate(0, 0, -int(now.Weekday()))
-	suggestionPeriodEnd := suggestionPeriodStart.AddDate(0, 0, cfg.SuggestionPeriodInDays)
-	votingPeriodEnd := suggestionPeriodEnd.AddDate(0, 0, cfg.VotePeriodInDays)
-	movieNightPeriodEnd := votingPeriodEnd.AddDate(0, 0, cfg.MovieNightPeriodInDays)
+	// January 4th is always in the first ISO week of its year
+	january4 := time.Date(week.IsoYear, time.January, 4, 0, 0, 0, 0, loc)
+	monday := january4.AddDate(0, 0, (week.IsoWeek-1)*7-(int(january4.Weekday())+6)%7)
+	start := time.Date(monday.Year(), monday.Month(), monday.Day()+(int(sched.start.Weekday)+6)%7,
+		sched.start.Hour, sched.start.Minute, 0, 0, loc)
 
-	if now.After(suggestionPeriodStart) && now.Before(suggestionPeriodEnd.AddDate(0, 0, 1)) {
-		curPeriod.Name = Suggesting
-		curPeriod.DaysLeft = int(suggestionPeriodEnd.Sub(now).Hours()) / 24
-	}
+	return LoadAppSettings(ctx, cfg, start, overrides)
+}
 
-	if now.After(suggestionPeriodEnd) && now.Before(votingPeriodEnd.AddDate(0, 0, 1)) {
-		curPeriod.Name = Voting
-		curPeriod.DaysLeft = int(votingPeriodEnd.Sub(now).Hours()) / 24
+// Reconfigure settings to a new time. This is especially useful for testing
+// purposes.
+func (settings *AppSettings) setTime(ctx context.Context, sched schedule, now time.Time, store OverrideStore) error {
+	now = now.In(&settings.Localization)
+	cycle, cycleErr := sched.cycleAt(now)
+	if cycleErr != nil {
+		return cycleErr
 	}
 
-	if now.After(votingPeriodEnd) && now.Before(movieNightPeriodEnd.AddDate(0, 0, 1)) {
-		curPeriod.Name = MovieNight
-		curPeriod.DaysLeft = int(movieNightPeriodEnd.Sub(now).Hours()) / 24
+	var overrides []Override
+	if store != nil {
+		var overridesErr error
+		if overrides, overridesErr = store.Overrides(ctx, cycle.WeekID()); overridesErr != nil {
+			return overridesErr
+		}
 	}
 
-	return curPeriod
+	settings.Now = now
+	settings.CurDay = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0,
+		0, &settings.Localization)
+	settings.CurCycle = cycle.Apply(overrides)
+	settings.WeekID = cycle.WeekID()
+	settings.CurPeriod = settings.CurCycle.Period(now)
+
+	return nil
+}
+
+// ClosedReason explains why something that needs the given period can't be
+// done right now.
+func (settings *AppSettings) ClosedReason(period PeriodName) string {
+	switch {
+	case settings.CurCycle.Skipped:
+		return "Movie night is skipped this week."
+	case settings.CurPeriod.Name < period:
+		return fmt.Sprintf("The %s period hasn't started yet.", period)
+	default:
+		return fmt.Sprintf("The %s period has already ended.", period)
+	}
 }
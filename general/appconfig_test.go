@@ -1,71 +1,175 @@
 package general
 
 import (
+	"context"
 	"testing"
 	"time"
 )
 
+// periodAt is the period the commands see at now.
+func periodAt(cfg AppConfig, now time.Time) (Period, error) {
+	settings, err := LoadAppSettings(context.Background(), cfg, now, nil)
+	if err != nil {
+		return Period{}, err
+	}
+
+	return settings.CurPeriod, nil
+}
+
+func chicago(t *testing.T) *time.Location {
+	loc, err := time.LoadLocation(DefaultConfiguration().Localization)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return loc
+}
+
 func TestGivenASuggestionPeriodDateStateIsInSuggesting(t *testing.T) {
 	cfg := DefaultConfiguration()
-	settings, _ := CreateAppSettings(cfg)
-	now := time.Date(2021, 4, 5, 0, 0, 0, 0, &settings.Localization)
+	now := time.Date(2021, 4, 5, 0, 0, 0, 0, chicago(t))
 
 	expected := Suggesting
-	actual := calculatePeriod(cfg, now)
+	actual, err := periodAt(cfg, now)
 
-	if expected != actual.Name || actual.DaysLeft != 2 {
+	if err != nil || expected != actual.Name || actual.Remaining != 72*time.Hour {
 		t.Fail()
 	}
 }
 
 func TestGivenAEndSuggestionPeriodDateStateIsInSuggesting(t *testing.T) {
 	cfg := DefaultConfiguration()
-	settings, _ := CreateAppSettings(cfg)
-	now := time.Date(2021, 4, 7, 0, 0, 0, 0, &settings.Localization)
+	now := time.Date(2021, 4, 7, 0, 0, 0, 0, chicago(t))
 
 	expected := Suggesting
-	actual := calculatePeriod(cfg, now)
+	actual, err := periodAt(cfg, now)
 
-	if expected != actual.Name || actual.DaysLeft != 0 {
+	if err != nil || expected != actual.Name || actual.Remaining != 24*time.Hour {
 		t.Fail()
 	}
 }
 
 func TestGivenAVotingDateStateIsInVoting(t *testing.T) {
 	cfg := DefaultConfiguration()
-	settings, _ := CreateAppSettings(cfg)
-	now := time.Date(2021, 4, 8, 0, 0, 0, 0, &settings.Localization)
+	now := time.Date(2021, 4, 8, 0, 0, 0, 0, chicago(t))
 
 	expected := Voting
-	actual := calculatePeriod(cfg, now)
+	actual, err := periodAt(cfg, now)
 
-	if expected != actual.Name || actual.DaysLeft != 0 {
+	if err != nil || expected != actual.Name || actual.Remaining != 24*time.Hour {
 		t.Fail()
 	}
 }
 
 func TestGivenAMovieNightDateStateIsInMovienight(t *testing.T) {
 	cfg := DefaultConfiguration()
-	settings, _ := CreateAppSettings(cfg)
-	now := time.Date(2021, 4, 9, 0, 0, 0, 0, &settings.Localization)
+	now := time.Date(2021, 4, 9, 0, 0, 0, 0, chicago(t))
 
 	expected := MovieNight
-	actual := calculatePeriod(cfg, now)
+	actual, err := periodAt(cfg, now)
 
-	if expected != actual.Name || actual.DaysLeft != 0 {
+	if err != nil || expected != actual.Name || actual.Remaining != 24*time.Hour {
 		t.Fail()
 	}
 }
 
 func TestGivenASleepDateStateIsInSleep(t *testing.T) {
 	cfg := DefaultConfiguration()
-	settings, _ := CreateAppSettings(cfg)
-	now := time.Date(2021, 4, 10, 0, 0, 0, 0, &settings.Localization)
+	now := time.Date(2021, 4, 10, 0, 0, 0, 0, chicago(t))
 
 	expected := Sleep
-	actual := calculatePeriod(cfg, now)
+	actual, err := periodAt(cfg, now)
+
+	if err != nil || expected != actual.Name || actual.Remaining != 48*time.Hour {
+		t.Fail()
+	}
+}
+
+func fridayMovieNightConfiguration() AppConfig {
+	cfg := DefaultConfiguration()
+	cfg.CycleStart = "Saturday 12:00"
+	cfg.SuggestingCloses = "Wednesday 18:00"
+	cfg.VotingCloses = "Friday 20:00"
+	cfg.MovieNightEnds = "Saturday 00:00"
+
+	return cfg
+}
+
+func TestGivenAnHourBoundaryStateChangesAtThatHour(t *testing.T) {
+	cfg := fridayMovieNightConfiguration()
+	loc, _ := time.LoadLocation(cfg.Localization)
+
+	before, _ := periodAt(cfg, time.Date(2021, 4, 9, 19, 59, 0, 0, loc))
+	after, _ := periodAt(cfg, time.Date(2021, 4, 9, 20, 0, 0, 0, loc))
+
+	if before.Name != Voting || before.Remaining != time.Minute {
+		t.Fail()
+	}
+
+	if after.Name != MovieNight || after.Remaining != 4*time.Hour {
+		t.Fail()
+	}
+}
+
+func TestGivenACycleStartingSaturdayTheWeekIDIsFromTheStart(t *testing.T) {
+	cfg := fridayMovieNightConfiguration()
+	loc, _ := time.LoadLocation(cfg.Localization)
+
+	// Monday 2021-04-05 belongs to the cycle started Saturday 2021-04-03
+	settings, err := LoadAppSettings(context.Background(), cfg, time.Date(2021, 4, 5, 9, 0, 0, 0, loc), nil)
+
+	if err != nil || settings.WeekID.String() != "202113" || settings.CurPeriod.Name != Suggesting {
+		t.Fail()
+	}
+
+	if !settings.CurCycle.Start.Equal(time.Date(2021, 4, 3, 12, 0, 0, 0, loc)) {
+		t.Fail()
+	}
+}
+
+func TestGivenAPastWeekWeekSettingsFindItsMovieNight(t *testing.T) {
+	cfg := fridayMovieNightConfiguration()
+	loc, _ := time.LoadLocation(cfg.Localization)
+
+	settings, err := WeekSettings(context.Background(), cfg, WeekID{IsoYear: 2021, IsoWeek: 13}, nil)
+
+	if err != nil || settings.WeekID.String() != "202113" || !settings.CurCycle.VotingCloses.Equal(time.Date(2021, 4, 9, 20, 0, 0, 0, loc)) {
+		t.Fail()
+	}
+}
+
+func TestGivenBeforeTheCycleStartHourStateIsInSleep(t *testing.T) {
+	cfg := fridayMovieNightConfiguration()
+	loc, _ := time.LoadLocation(cfg.Localization)
+
+	actual, err := periodAt(cfg, time.Date(2021, 4, 10, 11, 0, 0, 0, loc))
 
-	if expected != actual.Name || actual.DaysLeft != 0 {
+	if err != nil || actual.Name != Sleep || actual.Remaining != time.Hour {
 		t.Fail()
 	}
 }
+
+func TestGivenPeriodsLongerThanAWeekSettingsFail(t *testing.T) {
+	cfg := DefaultConfiguration()
+	cfg.MovieNightEnds = "Monday 01:00"
+
+	if _, err := LoadAppSettings(context.Background(), cfg, time.Date(2021, 4, 5, 0, 0, 0, 0, chicago(t)), nil); err == nil {
+		t.Fail()
+	}
+}
+
+func TestGivenABoundaryItParsesTheWeekdayAndTime(t *testing.T) {
+	boundary, err := ParseBoundary("thu 18:30")
+
+	if err != nil || boundary.Weekday != time.Thursday || boundary.Hour != 18 || boundary.Minute != 30 {
+		t.Fail()
+	}
+}
+
+func TestGivenAnInvalidBoundaryParsingFails(t *testing.T) {
+	for _, raw := range []string{"", "Funday", "Friday 25:00", "Friday 8pm tonight"} {
+		if _, err := ParseBoundary(raw); err == nil {
+			t.Errorf("expected \"%s\" to fail", raw)
+		}
+	}
+}
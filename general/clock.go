@@ -0,0 +1,23 @@
+package general
+
+import "time"
+
+// Clock tells the app what time it is. The periods and week depend on it, so
+// tests and long running processes can decide where the time comes from.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the wall clock.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock always returns the same time.
+type FixedClock time.Time
+
+func (clock FixedClock) Now() time.Time {
+	return time.Time(clock)
+}
@@ -0,0 +1,210 @@
+package general
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultConfigFile is read when it exists and MOV_CONFIG doesn't name
+// another file.
+const DefaultConfigFile = "mov.yaml"
+
+// ConfigSources records where each configuration value came from, keyed by
+// config name. Values that were never overridden aren't recorded.
+type ConfigSources map[string]string
+
+// Source describes where the named value came from.
+func (sources ConfigSources) Source(name string) string {
+	if source, exists := sources[name]; exists {
+		return source
+	}
+
+	return "default"
+}
+
+// ConfigField describes a configurable value of AppConfig.
+type ConfigField struct {
+	Name   string
+	Usage  string
+	Secret bool
+	index  int
+}
+
+// EnvName is the environment variable that sets the field.
+func (field ConfigField) EnvName() string {
+	return "MOV_" + strings.ToUpper(strings.ReplaceAll(field.Name, "-", "_"))
+}
+
+// ConfigFields lists the configurable values in the order AppConfig declares
+// them.
+func ConfigFields() []ConfigField {
+	configType := reflect.TypeOf(AppConfig{})
+
+	var fields []ConfigField
+	for i := 0; i < configType.NumField(); i++ {
+		tag := configType.Field(i).Tag
+		if tag.Get("config") == "" {
+			continue
+		}
+
+		fields = append(fields, ConfigField{
+			Name:   tag.Get("config"),
+			Usage:  tag.Get("usage"),
+			Secret: tag.Get("secret") == "true",
+			index:  i,
+		})
+	}
+
+	return fields
+}
+
+func lookupConfigField(name string) (ConfigField, bool) {
+	for _, field := range ConfigFields() {
+		if field.Name == name {
+			return field, true
+		}
+	}
+
+	return ConfigField{}, false
+}
+
+// Get formats the named value as a string.
+func (cfg AppConfig) Get(name string) string {
+	field, exists := lookupConfigField(name)
+	if !exists {
+		return ""
+	}
+
+	value := reflect.ValueOf(cfg).Field(field.index)
+	if value.Kind() == reflect.Slice {
+		return strings.Join(value.Interface().([]string), ",")
+	}
+
+	return fmt.Sprint(value.Interface())
+}
+
+// Set parses the string into the named value. Lists are comma separated.
+func (cfg *AppConfig) Set(name string, raw string) error {
+	field, exists := lookupConfigField(name)
+	if !exists {
+		return fmt.Errorf("unknown configuration \"%s\"", name)
+	}
+
+	value := reflect.ValueOf(cfg).Elem().Field(field.index)
+	raw = strings.TrimSpace(raw)
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("configuration \"%s\" must be a whole number, got \"%s\"", name, raw)
+		}
+		value.SetInt(int64(parsed))
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("configuration \"%s\" must be true or false, got \"%s\"", name, raw)
+		}
+		value.SetBool(parsed)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("configuration \"%s\" can't be set", name)
+	}
+
+	return nil
+}
+
+// LoadConfig layers the configuration from lowest to highest precedence:
+// the defaults, the config file, then MOV_* environment variables. Global
+// flags are layered on top when a command runs.
+//
+// The config file is YAML named by MOV_CONFIG, or mov.yaml when it exists.
+func LoadConfig(environ []string) (AppConfig, ConfigSources, error) {
+	cfg := DefaultConfiguration()
+	sources := ConfigSources{}
+	env := environMap(environ)
+
+	path, explicit := env["MOV_CONFIG"]
+	if !explicit {
+		path = DefaultConfigFile
+	}
+
+	contents, readErr := ioutil.ReadFile(path)
+	switch {
+	case readErr == nil:
+		if err := cfg.applyFile(path, contents, sources); err != nil {
+			return cfg, sources, err
+		}
+	case explicit || !os.IsNotExist(readErr):
+		return cfg, sources, fmt.Errorf("unable to read config file: %w", readErr)
+	}
+
+	for _, field := range ConfigFields() {
+		raw, exists := env[field.EnvName()]
+		if !exists {
+			continue
+		}
+
+		if err := cfg.Set(field.Name, raw); err != nil {
+			return cfg, sources, fmt.Errorf("%s: %w", field.EnvName(), err)
+		}
+		sources[field.Name] = "env " + field.EnvName()
+	}
+
+	return cfg, sources, nil
+}
+
+func (cfg *AppConfig) applyFile(path string, contents []byte, sources ConfigSources) error {
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(contents, &values); err != nil {
+		return fmt.Errorf("unable to parse config file %s: %w", path, err)
+	}
+
+	for name, value := range values {
+		raw := fmt.Sprint(value)
+		if value == nil {
+			raw = ""
+		}
+
+		if list, isList := value.([]interface{}); isList {
+			items := make([]string, len(list))
+			for i, item := range list {
+				items[i] = fmt.Sprint(item)
+			}
+			raw = strings.Join(items, ",")
+		}
+
+		if err := cfg.Set(name, raw); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		sources[name] = "file " + path
+	}
+
+	return nil
+}
+
+func environMap(environ []string) map[string]string {
+	env := make(map[string]string, len(environ))
+	for _, entry := range environ {
+		if i := strings.Index(entry, "="); i > 0 {
+			env[entry[:i]] = entry[i+1:]
+		}
+	}
+
+	return env
+}
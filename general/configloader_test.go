@@ -0,0 +1,72 @@
+package general
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "mov.yaml")
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestGivenNoConfigSourcesLoadConfigUsesDefaults(t *testing.T) {
+	cfg, sources, err := LoadConfig([]string{"MOV_CONFIG=" + writeConfigFile(t, "")})
+
+	if err != nil || cfg.TallyMethod != DefaultConfiguration().TallyMethod || sources.Source("tally-method") != "default" {
+		t.Fail()
+	}
+}
+
+func TestGivenAConfigFileLoadConfigOverridesDefaults(t *testing.T) {
+	path := writeConfigFile(t, "voting-closes: Friday 20:00\ntally-method: schulze\n")
+
+	cfg, sources, err := LoadConfig([]string{"MOV_CONFIG=" + path})
+
+	if err != nil || cfg.VotingCloses != "Friday 20:00" || cfg.TallyMethod != "schulze" {
+		t.Fail()
+	}
+
+	if sources.Source("voting-closes") != "file "+path {
+		t.Fail()
+	}
+}
+
+func TestGivenAnEnvironmentVariableLoadConfigOverridesTheConfigFile(t *testing.T) {
+	path := writeConfigFile(t, "tally-method: schulze\n")
+
+	cfg, sources, err := LoadConfig([]string{"MOV_CONFIG=" + path, "MOV_TALLY_METHOD=borda"})
+
+	if err != nil || cfg.TallyMethod != "borda" || sources.Source("tally-method") != "env MOV_TALLY_METHOD" {
+		t.Fail()
+	}
+}
+
+func TestGivenAMissingExplicitConfigFileLoadConfigFails(t *testing.T) {
+	_, _, err := LoadConfig([]string{"MOV_CONFIG=" + filepath.Join(t.TempDir(), "missing.yaml")})
+
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestGivenAnUnknownConfigNameLoadConfigFails(t *testing.T) {
+	_, _, err := LoadConfig([]string{"MOV_CONFIG=" + writeConfigFile(t, "voting-close: Friday 20:00\n")})
+
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestGivenAnUnknownConfigNameSetFails(t *testing.T) {
+	cfg := DefaultConfiguration()
+
+	if err := cfg.Set("voting-close", "Friday 20:00"); err == nil {
+		t.Fail()
+	}
+}
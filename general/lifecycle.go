@@ -0,0 +1,152 @@
+package general
+
+import (
+	"context"
+	"time"
+)
+
+func (name PeriodName) String() string {
+	switch name {
+	case Suggesting:
+		return "suggesting"
+	case Voting:
+		return "voting"
+	case MovieNight:
+		return "movie night"
+	default:
+		return "sleep"
+	}
+}
+
+// Transition is emitted when the lifecycle sees a week move from one period
+// to another. Periods that passed while nobody was watching are skipped, so
+// subscribers should react to the period being entered.
+type Transition struct {
+	WeekID WeekID
+	From   PeriodName
+	To     PeriodName
+	At     time.Time
+}
+
+// Opened reports whether the transition entered the given period.
+func (transition Transition) Opened(period PeriodName) bool {
+	return transition.To == period && transition.From != period
+}
+
+// Subscriber reacts to period transitions, for example announcing the ballot
+// once voting opens.
+type Subscriber interface {
+	OnTransition(ctx context.Context, transition Transition) error
+}
+
+// SubscriberFunc adapts a function to a Subscriber.
+type SubscriberFunc func(ctx context.Context, transition Transition) error
+
+func (f SubscriberFunc) OnTransition(ctx context.Context, transition Transition) error {
+	return f(ctx, transition)
+}
+
+// PeriodStore remembers the last period the lifecycle saw for each week.
+type PeriodStore interface {
+	// LastPeriod returns false when the week hasn't been seen.
+	LastPeriod(ctx context.Context, weekID WeekID) (PeriodName, bool, error)
+	SavePeriod(ctx context.Context, weekID WeekID, period PeriodName, at time.Time) error
+}
+
+// Lifecycle turns the period calculated from the clock into transition
+// events. Call Check periodically; it only emits when the period differs
+// from the one stored for the week.
+type Lifecycle struct {
+	config      AppConfig
+	store       PeriodStore
+	overrides   OverrideStore
+	clock       Clock
+	subscribers []Subscriber
+}
+
+// NewLifecycle creates a lifecycle for the configured schedule. The week's
+// admin overrides are applied when the override store isn't nil.
+func NewLifecycle(cfg AppConfig, store PeriodStore, overrides OverrideStore, clock Clock) *Lifecycle {
+	return &Lifecycle{
+		config:    cfg,
+		store:     store,
+		overrides: overrides,
+		clock:     clock,
+	}
+}
+
+// Subscribe adds a subscriber notified of every transition in the order
+// subscribed.
+func (lifecycle *Lifecycle) Subscribe(subscriber Subscriber) {
+	lifecycle.subscribers = append(lifecycle.subscribers, subscriber)
+}
+
+// Check compares the current period to the last one seen for the week and
+// notifies the subscribers when it changed. A week that hasn't been seen
+// starts from Sleep. The new period is stored before subscribers are told so
+// a failing subscriber doesn't repeat the transition on the next check; the
+// first subscriber error is returned after every subscriber ran.
+func (lifecycle *Lifecycle) Check(ctx context.Context) (*Transition, error) {
+	now := lifecycle.clock.Now()
+	settings, settingsErr := LoadAppSettings(ctx, lifecycle.config, now, lifecycle.overrides)
+	if settingsErr != nil {
+		return nil, settingsErr
+	}
+
+	last, seen, lastErr := lifecycle.store.LastPeriod(ctx, settings.WeekID)
+	if lastErr != nil {
+		return nil, lastErr
+	}
+
+	if !seen {
+		last = Sleep
+	}
+
+	if seen && last == settings.CurPeriod.Name {
+		return nil, nil
+	}
+
+	if err := lifecycle.store.SavePeriod(ctx, settings.WeekID, settings.CurPeriod.Name, now); err != nil {
+		return nil, err
+	}
+
+	if !seen && settings.CurPeriod.Name == Sleep {
+		// Nothing happened yet, there is no transition to emit
+		return nil, nil
+	}
+
+	transition := Transition{
+		WeekID: settings.WeekID,
+		From:   last,
+		To:     settings.CurPeriod.Name,
+		At:     now,
+	}
+
+	var subscriberErr error
+	for _, subscriber := range lifecycle.subscribers {
+		if err := subscriber.OnTransition(ctx, transition); err != nil && subscriberErr == nil {
+			subscriberErr = err
+		}
+	}
+
+	return &transition, subscriberErr
+}
+
+// Watch checks for transitions every interval until the context is done.
+// Errors are passed to onError so a bad check doesn't stop the watch.
+func (lifecycle *Lifecycle) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := lifecycle.Check(ctx); err != nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package general
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type testPeriodStore map[WeekID]PeriodName
+
+func (store testPeriodStore) LastPeriod(ctx context.Context, weekID WeekID) (PeriodName, bool, error) {
+	period, seen := store[weekID]
+	return period, seen, nil
+}
+
+func (store testPeriodStore) SavePeriod(ctx context.Context, weekID WeekID, period PeriodName, at time.Time) error {
+	store[weekID] = period
+	return nil
+}
+
+type testClock struct {
+	now time.Time
+}
+
+func (clock *testClock) Now() time.Time {
+	return clock.now
+}
+
+func newTestLifecycle(t *testing.T, now time.Time) (*Lifecycle, *testClock, *[]Transition) {
+	clock := &testClock{now}
+	lifecycle := NewLifecycle(DefaultConfiguration(), testPeriodStore{}, nil, clock)
+
+	transitions := &[]Transition{}
+	lifecycle.Subscribe(SubscriberFunc(func(ctx context.Context, transition Transition) error {
+		*transitions = append(*transitions, transition)
+		return nil
+	}))
+
+	return lifecycle, clock, transitions
+}
+
+func chicagoTime(t *testing.T, day int, hour int) time.Time {
+	loc, err := time.LoadLocation(DefaultConfiguration().Localization)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return time.Date(2021, 4, day, hour, 0, 0, 0, loc)
+}
+
+func TestGivenTheWeekMovesThroughEachPeriodTheLifecycleEmitsEachTransition(t *testing.T) {
+	lifecycle, clock, transitions := newTestLifecycle(t, chicagoTime(t, 5, 12))
+	ctx := context.Background()
+
+	for _, day := range []int{5, 6, 8, 9, 10} {
+		clock.now = chicagoTime(t, day, 12)
+		if _, err := lifecycle.Check(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []Transition{
+		{From: Sleep, To: Suggesting},
+		{From: Suggesting, To: Voting},
+		{From: Voting, To: MovieNight},
+		{From: MovieNight, To: Sleep},
+	}
+
+	if len(*transitions) != len(expected) {
+		t.Fatalf("expected %d transitions, got %v", len(expected), *transitions)
+	}
+
+	for i, transition := range *transitions {
+		if transition.From != expected[i].From || transition.To != expected[i].To || transition.WeekID.String() != "202114" {
+			t.Errorf("transition %d was %v", i, transition)
+		}
+	}
+}
+
+func TestGivenAMissedPeriodTheLifecycleEmitsOneTransitionToTheCurrentPeriod(t *testing.T) {
+	lifecycle, clock, transitions := newTestLifecycle(t, chicagoTime(t, 5, 12))
+	ctx := context.Background()
+
+	lifecycle.Check(ctx)
+	clock.now = chicagoTime(t, 9, 12)
+	transition, err := lifecycle.Check(ctx)
+
+	if err != nil || transition == nil || transition.From != Suggesting || transition.To != MovieNight || len(*transitions) != 2 {
+		t.Fail()
+	}
+
+	if transition.Opened(Voting) || !transition.Opened(MovieNight) {
+		t.Fail()
+	}
+}
+
+func TestGivenNoPeriodChangeTheLifecycleEmitsNothing(t *testing.T) {
+	lifecycle, clock, transitions := newTestLifecycle(t, chicagoTime(t, 5, 12))
+	ctx := context.Background()
+
+	lifecycle.Check(ctx)
+	clock.now = chicagoTime(t, 5, 13)
+	transition, err := lifecycle.Check(ctx)
+
+	if err != nil || transition != nil || len(*transitions) != 1 {
+		t.Fail()
+	}
+}
+
+func TestGivenAFailingSubscriberTheOthersAreStillNotified(t *testing.T) {
+	lifecycle, _, _ := newTestLifecycle(t, chicagoTime(t, 5, 12))
+	notified := false
+	lifecycle.Subscribe(SubscriberFunc(func(ctx context.Context, transition Transition) error {
+		return errors.New("webhook is down")
+	}))
+	lifecycle.Subscribe(SubscriberFunc(func(ctx context.Context, transition Transition) error {
+		notified = true
+		return nil
+	}))
+
+	_, err := lifecycle.Check(context.Background())
+
+	if err == nil || !notified {
+		t.Fail()
+	}
+}
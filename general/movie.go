@@ -80,3 +80,111 @@ func removeArticles(s string) string {
 
 	return buf.String()
 }
+
+var romanNumerals = map[string]string{
+	"ii":    "2",
+	"iii":   "3",
+	"iv":    "4",
+	"v":     "5",
+	"vi":    "6",
+	"vii":   "7",
+	"viii":  "8",
+	"ix":    "9",
+	"x":     "10",
+	"xi":    "11",
+	"xii":   "12",
+	"xiii":  "13",
+	"xiv":   "14",
+	"xv":    "15",
+	"xvi":   "16",
+	"xvii":  "17",
+	"xviii": "18",
+	"xix":   "19",
+	"xx":    "20",
+}
+
+// numerals splits the title into its words without numbers and the numbers
+// it contains, with roman numerals written as digits. "I" is left alone since
+// it is more often a word than a one.
+func (m Movie) numerals() (string, []string) {
+	pattern := regexp.MustCompile(`[^\pL\pM\pN]+`)
+
+	var words []string
+	var numbers []string
+	for _, word := range pattern.Split(strings.ToLower(m.String()), -1) {
+		if arabic, isRoman := romanNumerals[word]; isRoman {
+			word = arabic
+		}
+
+		if word != "" && strings.Trim(word, "0123456789") == "" {
+			numbers = append(numbers, strings.TrimLeft(word, "0"))
+			continue
+		}
+
+		words = append(words, word)
+	}
+
+	return strings.Join(words, " "), numbers
+}
+
+// Similar tells if the movies are likely the same, such as "Shrek 2" and
+// "Shrek II" or a typo like "Shreck". Numbers must match exactly so sequels
+// aren't taken for each other, the rest may differ by an edit for every four
+// characters of the encoded title.
+func (m Movie) Similar(other Movie) bool {
+	words, numbers := m.numerals()
+	otherWords, otherNumbers := other.numerals()
+
+	if strings.Join(numbers, " ") != strings.Join(otherNumbers, " ") {
+		return false
+	}
+
+	encoded := Movie(words).Encode()
+	otherEncoded := Movie(otherWords).Encode()
+
+	longest := len([]rune(encoded))
+	if otherLength := len([]rune(otherEncoded)); otherLength > longest {
+		longest = otherLength
+	}
+
+	return editDistance(encoded, otherEncoded) <= longest/4
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a string, b string) int {
+	ar := []rune(a)
+	br := []rune(b)
+
+	previous := make([]int, len(br)+1)
+	current := make([]int, len(br)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		current[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(br)]
+}
+
+func min(values ...int) int {
+	smallest := values[0]
+	for _, v := range values[1:] {
+		if v < smallest {
+			smallest = v
+		}
+	}
+
+	return smallest
+}
@@ -56,3 +56,27 @@ func TestGivenMovieNameWithSubsequentRepeatingCharactersAndVowelsAreThenReplaced
 		t.Fail()
 	}
 }
+
+func TestGivenARomanNumeralSequelMoviesAreSimilar(t *testing.T) {
+	if !MovieFromString("Shrek 2").Similar(MovieFromString("Shrek II")) {
+		t.Fail()
+	}
+}
+
+func TestGivenATypoMoviesAreSimilar(t *testing.T) {
+	if !MovieFromString("Shreck").Similar(MovieFromString("Shrek")) {
+		t.Fail()
+	}
+}
+
+func TestGivenDifferentSequelNumbersMoviesAreNotSimilar(t *testing.T) {
+	if MovieFromString("Shrek 2").Similar(MovieFromString("Shrek")) || MovieFromString("Rocky III").Similar(MovieFromString("Rocky IV")) {
+		t.Fail()
+	}
+}
+
+func TestGivenShortDifferentTitlesMoviesAreNotSimilar(t *testing.T) {
+	if MovieFromString("Up").Similar(MovieFromString("It")) || MovieFromString("Cars").Similar(MovieFromString("Coco")) {
+		t.Fail()
+	}
+}
@@ -0,0 +1,143 @@
+package general
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type OverrideAction string
+
+const (
+	// ExtendPeriod pushes the end of a period, and the periods after it,
+	// back by an amount of time.
+	ExtendPeriod OverrideAction = "extend"
+	// ClosePeriod ends a period, and any before it, at a point in time.
+	ClosePeriod OverrideAction = "close"
+	// OpenPeriod starts a period at a point in time by closing the one
+	// before it.
+	OpenPeriod OverrideAction = "open"
+	// SkipWeek puts the whole week to sleep, for holidays.
+	SkipWeek OverrideAction = "skip"
+)
+
+// Override is an admin adjustment to a week's periods. Overrides of a week
+// are applied in the order they were made.
+type Override struct {
+	Action OverrideAction
+	Period PeriodName
+	Amount time.Duration
+	At     time.Time
+	Author string
+}
+
+func (override Override) String() string {
+	switch override.Action {
+	case ExtendPeriod:
+		return fmt.Sprintf("%s extended %s by %s", override.Author, override.Period, override.Amount)
+	case ClosePeriod:
+		return fmt.Sprintf("%s closed %s", override.Author, override.Period)
+	case OpenPeriod:
+		return fmt.Sprintf("%s opened %s", override.Author, override.Period)
+	default:
+		return fmt.Sprintf("%s skipped the week", override.Author)
+	}
+}
+
+// OverrideStore keeps the admin overrides of each week.
+type OverrideStore interface {
+	Overrides(ctx context.Context, weekID WeekID) ([]Override, error)
+	SaveOverride(ctx context.Context, weekID WeekID, override Override) error
+}
+
+// Apply adjusts the cycle by each override in order. Periods never run into
+// the next cycle.
+func (cycle Cycle) Apply(overrides []Override) Cycle {
+	for _, override := range overrides {
+		ends := []*time.Time{&cycle.SuggestingCloses, &cycle.VotingCloses, &cycle.MovieNightEnds}
+
+		switch override.Action {
+		case SkipWeek:
+			cycle.Skipped = true
+		case ExtendPeriod:
+			if int(override.Period) >= len(ends) {
+				continue
+			}
+
+			for _, end := range ends[override.Period:] {
+				*end = end.Add(override.Amount)
+				if end.After(cycle.NextStart) {
+					*end = cycle.NextStart
+				}
+			}
+		case ClosePeriod, OpenPeriod:
+			last := int(override.Period)
+			if override.Action == OpenPeriod {
+				last--
+			}
+
+			if last < 0 || last >= len(ends) {
+				continue
+			}
+
+			for _, end := range ends[:last+1] {
+				if end.After(override.At) {
+					*end = override.At
+				}
+			}
+		}
+	}
+
+	return cycle
+}
+
+// ParsePeriodName reads a period name such as "voting" or "movie-night".
+func ParsePeriodName(raw string) (PeriodName, error) {
+	switch strings.ToLower(strings.NewReplacer("-", "", " ", "", "_", "").Replace(raw)) {
+	case "suggesting", "suggestion", "suggestions":
+		return Suggesting, nil
+	case "voting", "vote", "votes":
+		return Voting, nil
+	case "movienight":
+		return MovieNight, nil
+	case "sleep":
+		return Sleep, nil
+	}
+
+	return Sleep, fmt.Errorf("\"%s\" is not a period, try suggesting, voting or movie-night", raw)
+}
+
+// ParseExtension reads an amount of time such as "1d", "12h" or "1d6h30m".
+// Days are always 24 hours.
+func ParseExtension(raw string) (time.Duration, error) {
+	invalid := fmt.Errorf("\"%s\" is not an amount of time such as \"1d\" or \"12h\"", raw)
+
+	var amount time.Duration
+	rest := strings.TrimSpace(raw)
+	if i := strings.Index(rest, "d"); i >= 0 {
+		days, err := strconv.Atoi(rest[:i])
+		if err != nil {
+			return 0, invalid
+		}
+
+		amount = time.Duration(days) * 24 * time.Hour
+		rest = rest[i+1:]
+	}
+
+	if rest != "" {
+		parsed, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, invalid
+		}
+
+		amount += parsed
+	}
+
+	if amount <= 0 || raw == "" {
+		return 0, invalid
+	}
+
+	return amount, nil
+}
@@ -0,0 +1,105 @@
+package general
+
+import (
+	"testing"
+	"time"
+)
+
+func defaultCycle(t *testing.T, now time.Time) Cycle {
+	sched, err := parseSchedule(DefaultConfiguration())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cycle, err := sched.cycleAt(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cycle
+}
+
+func TestGivenAnExtendedPeriodTheLaterPeriodsMoveBack(t *testing.T) {
+	cycle := defaultCycle(t, chicagoTime(t, 8, 12)).Apply([]Override{
+		{Action: ExtendPeriod, Period: Voting, Amount: 24 * time.Hour},
+	})
+
+	if !cycle.VotingCloses.Equal(chicagoTime(t, 10, 0)) || !cycle.MovieNightEnds.Equal(chicagoTime(t, 11, 0)) {
+		t.Fail()
+	}
+
+	if cycle.Period(chicagoTime(t, 9, 12)).Name != Voting {
+		t.Fail()
+	}
+}
+
+func TestGivenAnExtensionPastTheWeekItStopsAtTheNextWeek(t *testing.T) {
+	cycle := defaultCycle(t, chicagoTime(t, 8, 12)).Apply([]Override{
+		{Action: ExtendPeriod, Period: MovieNight, Amount: 7 * 24 * time.Hour},
+	})
+
+	if !cycle.MovieNightEnds.Equal(cycle.NextStart) {
+		t.Fail()
+	}
+}
+
+func TestGivenAClosedPeriodTheNextPeriodOpens(t *testing.T) {
+	closedAt := chicagoTime(t, 6, 12)
+	cycle := defaultCycle(t, closedAt).Apply([]Override{
+		{Action: ClosePeriod, Period: Suggesting, At: closedAt},
+	})
+
+	if cycle.Period(closedAt).Name != Voting || !cycle.VotingCloses.Equal(chicagoTime(t, 9, 0)) {
+		t.Fail()
+	}
+}
+
+func TestGivenAnOpenedPeriodTheEarlierPeriodsClose(t *testing.T) {
+	openedAt := chicagoTime(t, 6, 12)
+	cycle := defaultCycle(t, openedAt).Apply([]Override{
+		{Action: OpenPeriod, Period: MovieNight, At: openedAt},
+	})
+
+	if cycle.Period(openedAt).Name != MovieNight || !cycle.SuggestingCloses.Equal(openedAt) {
+		t.Fail()
+	}
+}
+
+func TestGivenASkippedWeekEveryPeriodIsSleep(t *testing.T) {
+	cycle := defaultCycle(t, chicagoTime(t, 5, 12)).Apply([]Override{{Action: SkipWeek}})
+
+	period := cycle.Period(chicagoTime(t, 8, 12))
+	if period.Name != Sleep || period.Remaining != 4*24*time.Hour-12*time.Hour {
+		t.Fail()
+	}
+}
+
+func TestGivenAnExtensionInDaysAndHoursItParses(t *testing.T) {
+	amount, err := ParseExtension("1d12h")
+
+	if err != nil || amount != 36*time.Hour {
+		t.Fail()
+	}
+}
+
+func TestGivenAnInvalidExtensionParsingFails(t *testing.T) {
+	for _, raw := range []string{"", "d", "tomorrow", "-1d", "0h"} {
+		if _, err := ParseExtension(raw); err == nil {
+			t.Errorf("expected \"%s\" to fail", raw)
+		}
+	}
+}
+
+func TestGivenAPeriodNameItParsesCommonSpellings(t *testing.T) {
+	for raw, expected := range map[string]PeriodName{"Suggesting": Suggesting, "votes": Voting, "movie-night": MovieNight} {
+		if actual, err := ParsePeriodName(raw); err != nil || actual != expected {
+			t.Errorf("expected \"%s\" to be %s", raw, expected)
+		}
+	}
+}
+
+func TestGivenADurationItFormatsDaysHoursAndMinutes(t *testing.T) {
+	if FormatDuration(26*time.Hour+30*time.Minute) != "1d 2h 30m" || FormatDuration(0) != "0m" {
+		t.Fail()
+	}
+}
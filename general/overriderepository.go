@@ -0,0 +1,56 @@
+package general
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+)
+
+// OverrideRepository stores the admin overrides of each week.
+type OverrideRepository struct {
+	session *storage.DB
+}
+
+func NewOverrideRepository(session *storage.DB) *OverrideRepository {
+	return &OverrideRepository{
+		session: session,
+	}
+}
+
+func (context *OverrideRepository) Overrides(ctx context.Context, weekID WeekID) ([]Override, error) {
+	rows, err := context.session.QueryContext(ctx, `
+		SELECT action, period, amount, effectiveAt, author
+		FROM week_overrides
+		WHERE weekID = ?
+		ORDER BY id`, weekID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	defer rows.Close()
+
+	var overrides []Override
+	for rows.Next() {
+		var override Override
+		var seconds int64
+		if err := rows.Scan(&override.Action, &override.Period, &seconds, &override.At, &override.Author); err != nil {
+			return nil, errors.Wrap(err, "")
+		}
+
+		override.Amount = time.Duration(seconds) * time.Second
+		overrides = append(overrides, override)
+	}
+
+	return overrides, errors.Wrap(rows.Err(), "")
+}
+
+func (context *OverrideRepository) SaveOverride(ctx context.Context, weekID WeekID, override Override) error {
+	_, err := context.session.ExecContext(ctx, `
+		INSERT INTO week_overrides (weekID, action, period, amount, effectiveAt, author)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		weekID.String(), override.Action, override.Period, int64(override.Amount/time.Second), override.At, override.Author)
+
+	return errors.Wrap(err, "")
+}
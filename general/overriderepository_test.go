@@ -0,0 +1,47 @@
+package general_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/dbtest"
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+)
+
+func TestGivenSavedOverridesTheyAreListedInOrderForTheirWeek(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, session *storage.DB) {
+		repository := general.NewOverrideRepository(session)
+		ctx := context.Background()
+		week := general.WeekID{IsoYear: 2021, IsoWeek: 14}
+		at := time.Date(2021, 4, 5, 12, 0, 0, 0, time.UTC)
+
+		saved := []general.Override{
+			{Action: general.ExtendPeriod, Period: general.Suggesting, Amount: 36 * time.Hour, At: at, Author: "olivia"},
+			{Action: general.ClosePeriod, Period: general.Voting, At: at.Add(time.Hour), Author: "noah"},
+		}
+		for _, override := range saved {
+			if err := repository.SaveOverride(ctx, week, override); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		skip := general.Override{Action: general.SkipWeek, At: at, Author: "olivia"}
+		if err := repository.SaveOverride(ctx, week.Previous(), skip); err != nil {
+			t.Fatal(err)
+		}
+
+		overrides, err := repository.Overrides(ctx, week)
+		if err != nil || len(overrides) != len(saved) {
+			t.Fatalf("expected %d overrides, got %v %+v", len(saved), overrides, err)
+		}
+
+		for i, override := range overrides {
+			if override.Action != saved[i].Action || override.Period != saved[i].Period || override.Amount != saved[i].Amount ||
+				!override.At.Equal(saved[i].At) || override.Author != saved[i].Author {
+				t.Fail()
+			}
+		}
+	})
+}
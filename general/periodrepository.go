@@ -0,0 +1,46 @@
+package general
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+)
+
+// PeriodRepository stores the lifecycle's last seen period per week.
+type PeriodRepository struct {
+	session *storage.DB
+}
+
+func NewPeriodRepository(session *storage.DB) *PeriodRepository {
+	return &PeriodRepository{
+		session: session,
+	}
+}
+
+func (context *PeriodRepository) LastPeriod(ctx context.Context, weekID WeekID) (PeriodName, bool, error) {
+	var period PeriodName
+	err := context.session.QueryRowContext(ctx, `SELECT period FROM period_states WHERE weekID = ?`, weekID.String()).Scan(&period)
+	if err == sql.ErrNoRows {
+		return Sleep, false, nil
+	}
+
+	if err != nil {
+		return Sleep, false, errors.Wrap(err, "")
+	}
+
+	return period, true, nil
+}
+
+func (context *PeriodRepository) SavePeriod(ctx context.Context, weekID WeekID, period PeriodName, at time.Time) error {
+	_, err := context.session.ExecContext(ctx, `
+		INSERT INTO period_states (weekID, period, dateChanged)
+		VALUES (?, ?, ?)
+		ON CONFLICT(weekID) DO UPDATE SET period = excluded.period, dateChanged = excluded.dateChanged`,
+		weekID.String(), period, at)
+
+	return errors.Wrap(err, "")
+}
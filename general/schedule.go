@@ -0,0 +1,203 @@
+package general
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Boundary is a point in the weekly cycle, such as "Thursday 18:00".
+type Boundary struct {
+	Weekday time.Weekday
+	Hour    int
+	Minute  int
+}
+
+func (b Boundary) String() string {
+	return fmt.Sprintf("%s %02d:%02d", b.Weekday, b.Hour, b.Minute)
+}
+
+// ParseBoundary reads a weekday followed by an optional 24 hour time of day,
+// for example "Friday 20:00", "fri 8:30" or "Monday". The time defaults to
+// midnight.
+func ParseBoundary(raw string) (Boundary, error) {
+	fields := strings.Fields(raw)
+	if len(fields) < 1 || len(fields) > 2 {
+		return Boundary{}, fmt.Errorf("\"%s\" is not a weekday and time such as \"Thursday 18:00\"", raw)
+	}
+
+	boundary := Boundary{}
+	weekday, weekdayErr := parseWeekday(fields[0])
+	if weekdayErr != nil {
+		return boundary, weekdayErr
+	}
+	boundary.Weekday = weekday
+
+	if len(fields) == 2 {
+		timeOfDay, timeErr := time.Parse("15:04", fields[1])
+		if timeErr != nil {
+			return boundary, fmt.Errorf("\"%s\" is not a time of day such as \"18:00\"", fields[1])
+		}
+		boundary.Hour = timeOfDay.Hour()
+		boundary.Minute = timeOfDay.Minute()
+	}
+
+	return boundary, nil
+}
+
+func parseWeekday(raw string) (time.Weekday, error) {
+	name := strings.ToLower(raw)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if name == full || (len(name) >= 3 && strings.HasPrefix(full, name)) {
+			return day, nil
+		}
+	}
+
+	return time.Sunday, fmt.Errorf("\"%s\" is not a weekday", raw)
+}
+
+// Cycle is one week of movie night: suggesting opens at Start, followed by
+// voting, movie night, then sleep until the next cycle starts.
+type Cycle struct {
+	Start            time.Time
+	SuggestingCloses time.Time
+	VotingCloses     time.Time
+	MovieNightEnds   time.Time
+	NextStart        time.Time
+	// Skipped weeks sleep from start to finish.
+	Skipped bool
+}
+
+// WeekID identifies the cycle by the week it starts in.
+func (cycle Cycle) WeekID() WeekID {
+	return WeekIDFromTime(cycle.Start)
+}
+
+// Period is the period of the cycle at the given time along with how long
+// until it ends.
+func (cycle Cycle) Period(now time.Time) Period {
+	if cycle.Skipped {
+		return Period{Name: Sleep, Remaining: cycle.NextStart.Sub(now)}
+	}
+
+	ends := []struct {
+		name PeriodName
+		at   time.Time
+	}{
+		{Suggesting, cycle.SuggestingCloses},
+		{Voting, cycle.VotingCloses},
+		{MovieNight, cycle.MovieNightEnds},
+		{Sleep, cycle.NextStart},
+	}
+
+	for _, end := range ends {
+		if now.Before(end.at) {
+			return Period{Name: end.name, Remaining: end.at.Sub(now)}
+		}
+	}
+
+	return Period{Name: Sleep}
+}
+
+// schedule holds the parsed period boundaries of the configuration.
+type schedule struct {
+	start            Boundary
+	suggestingCloses Boundary
+	votingCloses     Boundary
+	movieNightEnds   Boundary
+}
+
+func parseSchedule(cfg AppConfig) (schedule, error) {
+	var sched schedule
+	boundaries := []struct {
+		name  string
+		raw   string
+		value *Boundary
+	}{
+		{"cycle-start", cfg.CycleStart, &sched.start},
+		{"suggesting-closes", cfg.SuggestingCloses, &sched.suggestingCloses},
+		{"voting-closes", cfg.VotingCloses, &sched.votingCloses},
+		{"movie-night-ends", cfg.MovieNightEnds, &sched.movieNightEnds},
+	}
+
+	for _, boundary := range boundaries {
+		parsed, err := ParseBoundary(boundary.raw)
+		if err != nil {
+			return sched, fmt.Errorf("configuration \"%s\": %w", boundary.name, err)
+		}
+		*boundary.value = parsed
+	}
+
+	return sched, nil
+}
+
+// cycleAt finds the cycle the given time falls in. Each boundary is the first
+// occurrence after the one before it, so a cycle spans at most one week.
+func (sched schedule) cycleAt(now time.Time) (Cycle, error) {
+	daysSinceStart := (int(now.Weekday()) - int(sched.start.Weekday) + 7) % 7
+	start := time.Date(now.Year(), now.Month(), now.Day()-daysSinceStart,
+		sched.start.Hour, sched.start.Minute, 0, 0, now.Location())
+	if start.After(now) {
+		start = start.AddDate(0, 0, -7)
+	}
+
+	cycle := Cycle{
+		Start:     start,
+		NextStart: start.AddDate(0, 0, 7),
+	}
+
+	previous := start
+	for _, boundary := range []struct {
+		at    Boundary
+		value *time.Time
+	}{
+		{sched.suggestingCloses, &cycle.SuggestingCloses},
+		{sched.votingCloses, &cycle.VotingCloses},
+		{sched.movieNightEnds, &cycle.MovieNightEnds},
+	} {
+		*boundary.value = sched.next(previous, boundary.at)
+		previous = *boundary.value
+	}
+
+	if cycle.MovieNightEnds.After(cycle.NextStart) {
+		return cycle, fmt.Errorf("the periods from %s to %s don't fit in one week",
+			sched.start, sched.movieNightEnds)
+	}
+
+	return cycle, nil
+}
+
+// next finds the first time after from that falls on the boundary.
+func (sched schedule) next(from time.Time, boundary Boundary) time.Time {
+	days := (int(boundary.Weekday) - int(from.Weekday()) + 7) % 7
+	at := time.Date(from.Year(), from.Month(), from.Day()+days,
+		boundary.Hour, boundary.Minute, 0, 0, from.Location())
+	if !at.After(from) {
+		at = at.AddDate(0, 0, 7)
+	}
+
+	return at
+}
+
+// FormatDuration rounds a duration to the minute and writes it in days, hours
+// and minutes, such as "1d 4h 30m".
+func FormatDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	if minutes <= 0 {
+		return "0m"
+	}
+
+	var parts []string
+	for _, unit := range []struct {
+		suffix  string
+		minutes int
+	}{{"d", 24 * 60}, {"h", 60}, {"m", 1}} {
+		if minutes >= unit.minutes {
+			parts = append(parts, fmt.Sprintf("%d%s", minutes/unit.minutes, unit.suffix))
+			minutes %= unit.minutes
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
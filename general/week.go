@@ -23,7 +23,18 @@ func WeekIDFromTime(t time.Time) WeekID {
 	}
 }
 
+// Previous is the ISO week before this one.
+func (w WeekID) Previous() WeekID {
+	// January 4th is always in the first ISO week of its year
+	inWeek := time.Date(w.IsoYear, time.January, 4, 12, 0, 0, 0, time.UTC).AddDate(0, 0, (w.IsoWeek-1)*7)
+	return WeekIDFromTime(inWeek.AddDate(0, 0, -7))
+}
+
 func WeekIDFromString(id string) (*WeekID, error) {
+	if len(id) != 6 {
+		return nil, fmt.Errorf("week id \"%s\" is not formatted as YYYYWW", id)
+	}
+
 	isoYear, err := strconv.Atoi(id[0:4])
 	if err != nil {
 		return nil, err
@@ -38,3 +38,11 @@ func TestGivenWeekIDStringConversionWorksCorrectly(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestGivenTheFirstWeekOfAYearPreviousIsTheLastWeekOfTheYearBefore(t *testing.T) {
+	previous := WeekID{IsoYear: 2021, IsoWeek: 1}.Previous()
+
+	if previous != (WeekID{IsoYear: 2020, IsoWeek: 53}) || (WeekID{IsoYear: 2021, IsoWeek: 15}).Previous() != (WeekID{IsoYear: 2021, IsoWeek: 14}) {
+		t.Fail()
+	}
+}
@@ -3,9 +3,13 @@ module github.com/fredlawl/200-colony-movie-night-bot
 go 1.16
 
 require (
+	github.com/bwmarrin/discordgo v0.27.1
 	github.com/google/uuid v1.2.0
+	github.com/lib/pq v1.9.0
 	github.com/mattn/go-sqlite3 v1.14.7
 	github.com/pkg/errors v0.9.1
 	github.com/urfave/cli/v2 v2.3.0
+	golang.org/x/crypto v0.0.0-20211117183948-ae814b36b871 // indirect
 	golang.org/x/text v0.3.6
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,67 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	description := `List past movie nights, latest first:
+    mov history [--limit 10]
+
+	The winner of each week is recorded when movie night starts. Watched movies can only be suggested again after the rewatch cooldown.
+`
+
+	return &cli.Command{
+		Name:        "history",
+		Aliases:     []string{"h"},
+		Usage:       "lists the movies already watched",
+		Description: description,
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:    "limit",
+				Aliases: []string{"n"},
+				Usage:   "number of movie nights to show",
+				Value:   10,
+			},
+		},
+		Action: historyAction,
+	}
+}
+
+func historyAction(c *cli.Context) error {
+	settings := c.App.Metadata["settings"].(*general.AppSettings)
+	historyRepository := c.App.Metadata["history"].(Store)
+
+	entries, err := historyRepository.Watched(c.Context)
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load the watch history.\n"))
+		return err
+	}
+
+	if len(entries) == 0 {
+		_, writeErr := c.App.Writer.Write([]byte("No movie nights yet.\n"))
+		return writeErr
+	}
+
+	if limit := c.Int("limit"); limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	var outputBuffer strings.Builder
+
+	outputBuffer.WriteString(fmt.Sprintf("%-8s%-12s%-33.32s%s\n", "Week", "Date", "Movie", "Suggested by"))
+	for _, entry := range entries {
+		outputBuffer.WriteString(fmt.Sprintf("%-8s%-12s%-33.32s%s\n",
+			entry.WeekID.String(),
+			entry.Watched.In(&settings.Localization).Format("2006-01-02"),
+			entry.Movie.String(),
+			entry.Author))
+	}
+
+	_, writeErr := c.App.Writer.Write([]byte(outputBuffer.String()))
+	return writeErr
+}
@@ -0,0 +1,119 @@
+package history_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/metadata"
+	"github.com/fredlawl/200-colony-movie-night-bot/movtest"
+)
+
+// watchShrek runs a week in which Shrek wins movie night, recorded by an
+// admin the way the bot does when movie night starts, then moves to the next
+// week.
+func watchShrek(h *movtest.Harness) {
+	h.Config.Admins = []string{"olivia"}
+	h.MustRun("liam", "suggestions add Shrek")
+	h.Weekday(time.Thursday)
+	h.MustRun("liam", "votes cast 1")
+	h.Weekday(time.Friday)
+	h.MustRun("olivia", "votes record")
+	h.Clock.Advance(3 * 24 * time.Hour)
+}
+
+func TestGivenAMovieNightHistoryListsTheWinner(t *testing.T) {
+	h := movtest.New(t)
+	watchShrek(h)
+
+	output := h.MustRun("noah", "history")
+
+	if !strings.Contains(output, "202114  2021-04-09  Shrek") || !strings.Contains(output, "liam") {
+		t.Fail()
+	}
+}
+
+func TestGivenAWatchedMovieItCantBeSuggestedDuringTheCooldown(t *testing.T) {
+	h := movtest.New(t)
+	watchShrek(h)
+
+	output := h.MustRun("noah", "suggestions add Shrek")
+
+	if !strings.Contains(output, "It can be suggested again after 2022-04-09.") || strings.Contains(h.MustRun("noah", "suggestions list"), "Shrek") {
+		t.Fail()
+	}
+}
+
+func TestGivenTheCooldownPassedAWatchedMovieIsAddedWithAWarning(t *testing.T) {
+	h := movtest.New(t)
+	watchShrek(h)
+	h.Clock.Set(h.Clock.Now().AddDate(1, 0, 0))
+	h.Weekday(time.Monday)
+
+	output := h.MustRun("noah", "suggestions add Shrek")
+
+	if !strings.Contains(output, "Heads up") || !strings.Contains(h.MustRun("noah", "suggestions list"), "Shrek") {
+		t.Fail()
+	}
+}
+
+func TestGivenNoCooldownAWatchedMovieIsAddedWithAWarning(t *testing.T) {
+	h := movtest.New(t)
+	h.Config.RewatchCooldown = 0
+	watchShrek(h)
+
+	output := h.MustRun("noah", "suggestions add Shrek")
+
+	if !strings.Contains(output, "Heads up") || !strings.Contains(h.MustRun("noah", "suggestions list"), "Shrek") {
+		t.Fail()
+	}
+}
+
+func TestGivenAWinnerRecordedLateItIsWatchedOnMovieNight(t *testing.T) {
+	h := movtest.New(t)
+	h.Config.Admins = []string{"olivia"}
+	h.MustRun("liam", "suggestions add Shrek")
+	h.Weekday(time.Thursday)
+	h.MustRun("liam", "votes cast 1")
+	h.Clock.Advance(7 * 24 * time.Hour)
+
+	h.MustRun("olivia", "votes record --week 202114")
+
+	if !strings.Contains(h.MustRun("noah", "history"), "202114  2021-04-09  Shrek") {
+		t.Fail()
+	}
+}
+
+func TestGivenAWatchedMovieItsCatalogNameIsInTheCooldown(t *testing.T) {
+	h := movtest.New(t)
+	watchShrek(h)
+	h.Database.Movies().Import(context.Background(), []metadata.Record{{ID: "tt0126029", Title: "Shrek", Year: 2001}})
+
+	output := h.MustRun("noah", "suggestions add shrek")
+
+	if !strings.Contains(output, "It can be suggested again after 2022-04-09.") || strings.Contains(h.MustRun("noah", "suggestions list"), "Shrek") {
+		t.Fail()
+	}
+}
+
+func TestGivenAWatchedCatalogMovieAnotherWithTheSameTitleCanBeSuggested(t *testing.T) {
+	h := movtest.New(t)
+	h.Database.Movies().Import(context.Background(), []metadata.Record{
+		{ID: "tt0087182", Title: "Dune", Year: 1984},
+		{ID: "tt1160419", Title: "Dune", Year: 2021},
+	})
+	h.Config.Admins = []string{"olivia"}
+	h.MustRun("liam", "suggestions add --movie tt0087182 Dune")
+	h.Weekday(time.Thursday)
+	h.MustRun("liam", "votes cast 1")
+	h.Weekday(time.Friday)
+	h.MustRun("olivia", "votes record")
+	h.Clock.Advance(3 * 24 * time.Hour)
+
+	output := h.MustRun("noah", "suggestions add --movie tt1160419 Dune")
+
+	if strings.Contains(output, "Sorry") || !strings.Contains(h.MustRun("noah", "suggestions list"), "Dune (2021)") {
+		t.Fail()
+	}
+}
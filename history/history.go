@@ -0,0 +1,70 @@
+// Package history remembers the movies already watched, the winners of past
+// movie nights, so they don't keep coming back.
+package history
+
+import (
+	"context"
+	"time"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/metadata"
+)
+
+// Entry is a past movie night.
+type Entry struct {
+	WeekID general.WeekID
+	Movie  general.Movie
+	// MovieID is the catalog id of the movie, empty when it isn't known.
+	MovieID string
+	// Author suggested the movie.
+	Author string
+	// Method is the counting method that picked the movie.
+	Method string
+	// Watched is when the week's movie night started.
+	Watched time.Time
+}
+
+// Store lists the movies watched.
+type Store interface {
+	// Watched lists every movie night, latest first.
+	Watched(ctx context.Context) ([]Entry, error)
+}
+
+// LastWatched returns the latest time the movie was watched, nil when it
+// never was. Movies from the catalog are matched by their id, others by
+// their encoded title without the year, so "Shrek" was watched when
+// "Shrek (2001)" was.
+func LastWatched(entries []Entry, movie general.Movie, movieID string) *Entry {
+	var last *Entry
+	for i, entry := range entries {
+		if !sameMovie(entry, movie, movieID) {
+			continue
+		}
+
+		if last == nil || entry.Watched.After(last.Watched) {
+			last = &entries[i]
+		}
+	}
+
+	return last
+}
+
+func sameMovie(entry Entry, movie general.Movie, movieID string) bool {
+	if entry.MovieID != "" && movieID != "" {
+		return entry.MovieID == movieID
+	}
+
+	title, year := metadata.ParseQuery(movie.String())
+	watchedTitle, watchedYear := metadata.ParseQuery(entry.Movie.String())
+	if year != 0 && watchedYear != 0 && year != watchedYear {
+		return false
+	}
+
+	return title.Encode() == watchedTitle.Encode()
+}
+
+// Available is when a movie watched at the entry can be suggested again.
+// Movies can always be suggested again when the cooldown is 0 months.
+func (entry Entry) Available(cooldownMonths int) time.Time {
+	return entry.Watched.AddDate(0, cooldownMonths, 0)
+}
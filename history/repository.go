@@ -0,0 +1,57 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+)
+
+// Repository is the SQL Store, for SQLite and PostgreSQL. It reads the
+// winners the votes record.
+type Repository struct {
+	session *storage.DB
+}
+
+func NewRepository(session *storage.DB) *Repository {
+	return &Repository{
+		session: session,
+	}
+}
+
+func (context *Repository) Watched(ctx context.Context) ([]Entry, error) {
+	rows, err := context.session.QueryContext(ctx, `
+		SELECT weekID, movie, movieID, author, method, dateDecided
+		FROM winners
+		ORDER BY weekID DESC`)
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var entry Entry
+		var weekID string
+		var movie string
+		var movieID sql.NullString
+		if err := rows.Scan(&weekID, &movie, &movieID, &entry.Author, &entry.Method, &entry.Watched); err != nil {
+			return nil, errors.Wrap(err, "")
+		}
+
+		parsedWeekID, err := general.WeekIDFromString(weekID)
+		if err != nil {
+			return nil, errors.Wrap(err, "")
+		}
+
+		entry.WeekID = *parsedWeekID
+		entry.Movie = general.MovieFromString(movie)
+		entry.MovieID = movieID.String
+		entries = append(entries, entry)
+	}
+
+	return entries, errors.Wrap(rows.Err(), "")
+}
@@ -0,0 +1,54 @@
+package history_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/dbtest"
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/history"
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+	"github.com/fredlawl/200-colony-movie-night-bot/suggestion"
+	"github.com/fredlawl/200-colony-movie-night-bot/vote"
+)
+
+// win saves the movie as the week's only suggestion and records it as the
+// winner.
+func win(t *testing.T, session *storage.DB, weekID general.WeekID, movie string, movieID string, watched time.Time) {
+	ctx := context.Background()
+	s, err := suggestion.NewSuggestion(weekID, "liam", general.MovieFromString(movie))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.MovieID = movieID
+
+	if err := suggestion.NewRepository(session).Save(ctx, *s); err != nil {
+		t.Fatal(err)
+	}
+
+	winner := vote.Candidate{ID: 1, Movie: s.Movie, Author: s.Author}
+	if err := vote.NewRepository(session).SaveWinner(ctx, weekID, winner, "irv", watched); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGivenRecordedWinnersWatchedListsTheLatestFirst(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, session *storage.DB) {
+		week := general.WeekID{IsoYear: 2021, IsoWeek: 14}
+		watched := time.Date(2021, 4, 9, 19, 0, 0, 0, time.UTC)
+		win(t, session, week.Previous(), "Cars", "", watched.AddDate(0, 0, -7))
+		win(t, session, week, "Dune (2021)", "tmdb:438631", watched)
+
+		entries, err := history.NewRepository(session).Watched(context.Background())
+		if err != nil || len(entries) != 2 {
+			t.Fatalf("expected 2 entries, got %v %+v", entries, err)
+		}
+
+		latest := entries[0]
+		if latest.WeekID != week || latest.Movie != "Dune (2021)" || latest.MovieID != "tmdb:438631" || latest.Author != "liam" ||
+			latest.Method != "irv" || !latest.Watched.Equal(watched) || entries[1].Movie != "Cars" || entries[1].MovieID != "" {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,631 @@
+// Package memory keeps everything mov stores in memory, with the same
+// constraints as the SQL schema, so commands can be tested without a
+// database.
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/auth"
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/history"
+	"github.com/fredlawl/200-colony-movie-night-bot/metadata"
+	"github.com/fredlawl/200-colony-movie-night-bot/suggestion"
+	"github.com/fredlawl/200-colony-movie-night-bot/vote"
+)
+
+type storedSuggestion struct {
+	suggestion suggestion.Suggestion
+	dateAdded  time.Time
+}
+
+type storedWinner struct {
+	weekID  general.WeekID
+	winner  vote.Candidate
+	method  string
+	decided time.Time
+}
+
+// Database holds the rows of every store. The stores it hands out share
+// them, so removing a suggestion removes its votes like the foreign key's
+// ON DELETE CASCADE does.
+type Database struct {
+	mu          sync.Mutex
+	clock       general.Clock
+	lastNumbers map[general.WeekID]suggestion.OrderedID
+	suggestions []storedSuggestion
+	votes       []vote.Vote
+	seconds     []suggestion.Second
+	renames     map[suggestion.ID][]suggestion.Rename
+	winners     map[general.WeekID]storedWinner
+	roles       map[string]auth.Role
+	overrides   map[general.WeekID][]general.Override
+	periods     map[general.WeekID]general.PeriodName
+	exceptions  map[general.WeekID]map[string]int
+	movies      *metadata.Catalog
+}
+
+// NewDatabase creates an empty database. The clock dates new suggestions.
+func NewDatabase(clock general.Clock) *Database {
+	return &Database{
+		clock:       clock,
+		lastNumbers: map[general.WeekID]suggestion.OrderedID{},
+		winners:     map[general.WeekID]storedWinner{},
+		roles:       map[string]auth.Role{},
+		overrides:   map[general.WeekID][]general.Override{},
+		periods:     map[general.WeekID]general.PeriodName{},
+		exceptions:  map[general.WeekID]map[string]int{},
+		renames:     map[suggestion.ID][]suggestion.Rename{},
+		movies:      metadata.NewCatalog(),
+	}
+}
+
+// Suggestions is the suggestion.Store of the database.
+func (db *Database) Suggestions() *Suggestions {
+	return &Suggestions{db}
+}
+
+// Votes is the vote.Store of the database.
+func (db *Database) Votes() *Votes {
+	return &Votes{db}
+}
+
+// Users is the auth.Store of the database.
+func (db *Database) Users() *Users {
+	return &Users{db}
+}
+
+// Overrides is the general.OverrideStore of the database.
+func (db *Database) Overrides() *Overrides {
+	return &Overrides{db}
+}
+
+// Movies is the metadata.Store of the database.
+func (db *Database) Movies() *metadata.Catalog {
+	return db.movies
+}
+
+// History is the history.Store of the database, reading its winners.
+func (db *Database) History() *History {
+	return &History{db}
+}
+
+// Periods is the general.PeriodStore of the database.
+func (db *Database) Periods() *Periods {
+	return &Periods{db}
+}
+
+// Seconds is the suggestion.SecondStore of the database.
+func (db *Database) Seconds() *Seconds {
+	return &Seconds{db}
+}
+
+// Exceptions is the suggestion.ExceptionStore of the database.
+func (db *Database) Exceptions() *Exceptions {
+	return &Exceptions{db}
+}
+
+func (db *Database) suggestionByOrder(weekID general.WeekID, orderID suggestion.OrderedID) (storedSuggestion, bool) {
+	for _, stored := range db.suggestions {
+		if stored.suggestion.WeekID == weekID && stored.suggestion.Order == orderID {
+			return stored, true
+		}
+	}
+
+	return storedSuggestion{}, false
+}
+
+type Suggestions struct {
+	db *Database
+}
+
+// Save enforces the unique (weekID, movieHash) and uuid indexes.
+func (store *Suggestions) Save(ctx context.Context, s suggestion.Suggestion) error {
+	db := store.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, stored := range db.suggestions {
+		if stored.suggestion.ID == s.ID {
+			return suggestion.ErrDuplicateMovie
+		}
+
+		if stored.suggestion.WeekID == s.WeekID && stored.suggestion.Movie.Encode() == s.Movie.Encode() {
+			return suggestion.ErrDuplicateMovie
+		}
+	}
+
+	// Numbers of removed suggestions aren't handed out again
+	db.lastNumbers[s.WeekID]++
+	s.Order = db.lastNumbers[s.WeekID]
+	db.suggestions = append(db.suggestions, storedSuggestion{
+		suggestion: s,
+		dateAdded:  db.clock.Now(),
+	})
+
+	return nil
+}
+
+func (store *Suggestions) AllSuggestions(ctx context.Context, weekID general.WeekID) ([]suggestion.Suggestion, error) {
+	db := store.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var week []suggestion.Suggestion
+	for _, stored := range db.suggestions {
+		if stored.suggestion.WeekID == weekID {
+			week = append(week, stored.suggestion)
+		}
+	}
+
+	return week, nil
+}
+
+func (store *Suggestions) GetSuggestionByOrder(ctx context.Context, weekID general.WeekID, orderID suggestion.OrderedID) (*suggestion.Suggestion, error) {
+	db := store.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, exists := db.suggestionByOrder(weekID, orderID)
+	if !exists {
+		return nil, suggestion.ErrUnknownSuggestion
+	}
+
+	return &stored.suggestion, nil
+}
+
+// Remove deletes the suggestion along with its votes, seconds and previous
+// titles.
+func (store *Suggestions) Remove(ctx context.Context, s suggestion.Suggestion) error {
+	db := store.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var removed []suggestion.Suggestion
+	kept := db.suggestions[:0]
+	for _, stored := range db.suggestions {
+		if stored.suggestion.ID == s.ID {
+			removed = append(removed, stored.suggestion)
+			continue
+		}
+		kept = append(kept, stored)
+	}
+	db.suggestions = kept
+
+	for _, r := range removed {
+		keptVotes := db.votes[:0]
+		for _, v := range db.votes {
+			if v.WeekID != r.WeekID || v.SuggestionOrderedID != r.Order {
+				keptVotes = append(keptVotes, v)
+			}
+		}
+		db.votes = keptVotes
+
+		keptSeconds := db.seconds[:0]
+		for _, second := range db.seconds {
+			if second.WeekID != r.WeekID || second.Order != r.Order {
+				keptSeconds = append(keptSeconds, second)
+			}
+		}
+		db.seconds = keptSeconds
+
+		delete(db.renames, r.ID)
+	}
+
+	return nil
+}
+
+// Rename enforces the unique (weekID, movieHash) index.
+func (store *Suggestions) Rename(ctx context.Context, s suggestion.Suggestion, by string) error {
+	db := store.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	renamed := -1
+	for i, stored := range db.suggestions {
+		if stored.suggestion.ID == s.ID {
+			renamed = i
+			continue
+		}
+
+		if stored.suggestion.WeekID == s.WeekID && stored.suggestion.Movie.Encode() == s.Movie.Encode() {
+			return suggestion.ErrDuplicateMovie
+		}
+	}
+
+	if renamed < 0 {
+		return suggestion.ErrUnknownSuggestion
+	}
+
+	previous := &db.suggestions[renamed].suggestion
+	db.renames[s.ID] = append(db.renames[s.ID], suggestion.Rename{
+		Movie:   previous.Movie,
+		MovieID: previous.MovieID,
+		By:      by,
+		Renamed: db.clock.Now(),
+	})
+
+	previous.Movie = s.Movie
+	previous.MovieID = s.MovieID
+
+	return nil
+}
+
+func (store *Suggestions) Renames(ctx context.Context, s suggestion.Suggestion) ([]suggestion.Rename, error) {
+	db := store.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return append([]suggestion.Rename(nil), db.renames[s.ID]...), nil
+}
+
+type Votes struct {
+	db *Database
+}
+
+// BulkSaveVotes replaces the author's votes for the week. Like the SQL
+// transaction nothing changes when any vote fails.
+func (store *Votes) BulkSaveVotes(ctx context.Context, author string, week general.WeekID, votes []vote.Vote) ([]vote.BulkVoteResult, error) {
+	db := store.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if len(votes) == 0 {
+		return []vote.BulkVoteResult{}, nil
+	}
+
+	var kept []vote.Vote
+	for _, v := range db.votes {
+		if v.WeekID != week || v.Author != author {
+			kept = append(kept, v)
+		}
+	}
+
+	hasErrors := false
+	results := make([]vote.BulkVoteResult, len(votes))
+	for i, v := range votes {
+		var err error
+		if _, exists := db.suggestionByOrder(v.WeekID, v.SuggestionOrderedID); !exists {
+			err = suggestion.ErrUnknownSuggestion
+		}
+
+		for _, existing := range kept {
+			if existing.WeekID == v.WeekID && existing.Author == v.Author && existing.SuggestionOrderedID == v.SuggestionOrderedID {
+				err = suggestion.ErrDuplicateMovie
+			}
+		}
+
+		results[i] = vote.NewBulkVoteResult(v, err)
+		hasErrors = hasErrors || err != nil
+		kept = append(kept, v)
+	}
+
+	if !hasErrors {
+		db.votes = kept
+	}
+
+	return results, nil
+}
+
+func (store *Votes) WithdrawVotes(ctx context.Context, author string, week general.WeekID) (int, error) {
+	db := store.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	withdrawn := 0
+	kept := db.votes[:0]
+	for _, v := range db.votes {
+		if v.WeekID == week && v.Author == author {
+			withdrawn++
+			continue
+		}
+		kept = append(kept, v)
+	}
+	db.votes = kept
+
+	return withdrawn, nil
+}
+
+func (store *Votes) SuggestionCnt(ctx context.Context, weekID general.WeekID) (int, error) {
+	db := store.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	count := 0
+	for _, stored := range db.suggestions {
+		if stored.suggestion.WeekID == weekID {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (store *Votes) Candidates(ctx context.Context, weekID general.WeekID) ([]vote.Candidate, error) {
+	db := store.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var candidates []vote.Candidate
+	for _, stored := range db.suggestions {
+		if stored.suggestion.WeekID != weekID {
+			continue
+		}
+
+		priorWins := 0
+		for _, won := range db.winners {
+			if won.winner.Author == stored.suggestion.Author && won.weekID.String() < weekID.String() {
+				priorWins++
+			}
+		}
+
+		candidates = append(candidates, vote.Candidate{
+			ID:        stored.suggestion.Order,
+			Movie:     stored.suggestion.Movie,
+			MovieID:   stored.suggestion.MovieID,
+			Author:    stored.suggestion.Author,
+			DateAdded: stored.dateAdded,
+			PriorWins: priorWins,
+		})
+	}
+
+	return candidates, nil
+}
+
+func (store *Votes) SaveWinner(ctx context.Context, weekID general.WeekID, winner vote.Candidate, method string, watched time.Time) error {
+	db := store.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, recorded := db.winners[weekID]; recorded {
+		return vote.ErrWinnerRecorded
+	}
+
+	if _, exists := db.suggestionByOrder(weekID, winner.ID); !exists {
+		return suggestion.ErrUnknownSuggestion
+	}
+
+	db.winners[weekID] = storedWinner{weekID, winner, method, watched}
+	return nil
+}
+
+func (store *Votes) Ballots(ctx context.Context, weekID general.WeekID) ([]vote.Ballot, error) {
+	db := store.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var week []vote.Vote
+	for _, v := range db.votes {
+		if v.WeekID == weekID {
+			week = append(week, v)
+		}
+	}
+
+	sort.SliceStable(week, func(i, j int) bool {
+		if week[i].Author != week[j].Author {
+			return week[i].Author < week[j].Author
+		}
+		return week[i].Preference < week[j].Preference
+	})
+
+	var ballots []vote.Ballot
+	for _, v := range week {
+		if len(ballots) == 0 || ballots[len(ballots)-1].Author != v.Author {
+			ballots = append(ballots, vote.Ballot{Author: v.Author})
+		}
+
+		last := &ballots[len(ballots)-1]
+		last.Ranking = append(last.Ranking, v.SuggestionOrderedID)
+	}
+
+	return ballots, nil
+}
+
+func (store *Votes) Ballot(ctx context.Context, author string, week general.WeekID) (*vote.Ballot, error) {
+	db := store.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var mine []vote.Vote
+	for _, v := range db.votes {
+		if v.WeekID == week && v.Author == author {
+			mine = append(mine, v)
+		}
+	}
+
+	if len(mine) == 0 {
+		return nil, nil
+	}
+
+	sort.Slice(mine, func(i, j int) bool {
+		return mine[i].Preference < mine[j].Preference
+	})
+
+	ballot := &vote.Ballot{Author: author}
+	for _, v := range mine {
+		ballot.Ranking = append(ballot.Ranking, v.SuggestionOrderedID)
+	}
+
+	return ballot, nil
+}
+
+type Users struct {
+	db *Database
+}
+
+func (store *Users) Role(ctx context.Context, user string) (auth.Role, error) {
+	store.db.mu.Lock()
+	defer store.db.mu.Unlock()
+
+	return store.db.roles[user], nil
+}
+
+func (store *Users) SetRole(ctx context.Context, user string, role auth.Role) error {
+	store.db.mu.Lock()
+	defer store.db.mu.Unlock()
+
+	store.db.roles[user] = role
+	return nil
+}
+
+func (store *Users) Roles(ctx context.Context) ([]auth.UserRole, error) {
+	store.db.mu.Lock()
+	defer store.db.mu.Unlock()
+
+	var roles []auth.UserRole
+	for user, role := range store.db.roles {
+		if role != auth.Member {
+			roles = append(roles, auth.UserRole{User: user, Role: role})
+		}
+	}
+
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].User < roles[j].User
+	})
+
+	return roles, nil
+}
+
+type Overrides struct {
+	db *Database
+}
+
+func (store *Overrides) Overrides(ctx context.Context, weekID general.WeekID) ([]general.Override, error) {
+	store.db.mu.Lock()
+	defer store.db.mu.Unlock()
+
+	return append([]general.Override(nil), store.db.overrides[weekID]...), nil
+}
+
+func (store *Overrides) SaveOverride(ctx context.Context, weekID general.WeekID, override general.Override) error {
+	store.db.mu.Lock()
+	defer store.db.mu.Unlock()
+
+	store.db.overrides[weekID] = append(store.db.overrides[weekID], override)
+	return nil
+}
+
+type History struct {
+	db *Database
+}
+
+func (store *History) Watched(ctx context.Context) ([]history.Entry, error) {
+	store.db.mu.Lock()
+	defer store.db.mu.Unlock()
+
+	var entries []history.Entry
+	for _, won := range store.db.winners {
+		entries = append(entries, history.Entry{
+			WeekID:  won.weekID,
+			Movie:   won.winner.Movie,
+			MovieID: won.winner.MovieID,
+			Author:  won.winner.Author,
+			Method:  won.method,
+			Watched: won.decided,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].WeekID.String() > entries[j].WeekID.String()
+	})
+
+	return entries, nil
+}
+
+type Periods struct {
+	db *Database
+}
+
+func (store *Periods) LastPeriod(ctx context.Context, weekID general.WeekID) (general.PeriodName, bool, error) {
+	store.db.mu.Lock()
+	defer store.db.mu.Unlock()
+
+	period, seen := store.db.periods[weekID]
+	return period, seen, nil
+}
+
+func (store *Periods) SavePeriod(ctx context.Context, weekID general.WeekID, period general.PeriodName, at time.Time) error {
+	store.db.mu.Lock()
+	defer store.db.mu.Unlock()
+
+	store.db.periods[weekID] = period
+	return nil
+}
+
+type Exceptions struct {
+	db *Database
+}
+
+func (store *Exceptions) GrantException(ctx context.Context, weekID general.WeekID, user string, grantedBy string) error {
+	store.db.mu.Lock()
+	defer store.db.mu.Unlock()
+
+	if store.db.exceptions[weekID] == nil {
+		store.db.exceptions[weekID] = map[string]int{}
+	}
+
+	store.db.exceptions[weekID][user]++
+	return nil
+}
+
+func (store *Exceptions) Exceptions(ctx context.Context, weekID general.WeekID, user string) (int, error) {
+	store.db.mu.Lock()
+	defer store.db.mu.Unlock()
+
+	return store.db.exceptions[weekID][user], nil
+}
+
+func (store *Exceptions) UseException(ctx context.Context, weekID general.WeekID, user string) (bool, error) {
+	store.db.mu.Lock()
+	defer store.db.mu.Unlock()
+
+	if store.db.exceptions[weekID][user] == 0 {
+		return false, nil
+	}
+
+	store.db.exceptions[weekID][user]--
+	return true, nil
+}
+
+type Seconds struct {
+	db *Database
+}
+
+// SaveSecond enforces the suggestion foreign key and the unique
+// (suggestionID, name) index.
+func (store *Seconds) SaveSecond(ctx context.Context, second suggestion.Second) error {
+	db := store.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.suggestionByOrder(second.WeekID, second.Order); !exists {
+		return suggestion.ErrUnknownSuggestion
+	}
+
+	for _, saved := range db.seconds {
+		if saved == second {
+			return suggestion.ErrAlreadySeconded
+		}
+	}
+
+	db.seconds = append(db.seconds, second)
+	return nil
+}
+
+func (store *Seconds) Seconds(ctx context.Context, weekID general.WeekID) ([]suggestion.Second, error) {
+	db := store.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var week []suggestion.Second
+	for _, second := range db.seconds {
+		if second.WeekID == weekID {
+			week = append(week, second)
+		}
+	}
+
+	return week, nil
+}
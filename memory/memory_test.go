@@ -0,0 +1,147 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/suggestion"
+	"github.com/fredlawl/200-colony-movie-night-bot/vote"
+)
+
+var ctx = context.Background()
+
+func testDatabase() *Database {
+	return NewDatabase(general.FixedClock(time.Date(2021, 4, 5, 12, 0, 0, 0, time.UTC)))
+}
+
+func testSuggestion(t *testing.T, db *Database, weekID general.WeekID, movie string) suggestion.Suggestion {
+	s, err := suggestion.NewSuggestion(weekID, "liam", general.MovieFromString(movie))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Suggestions().Save(ctx, *s); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := db.Suggestions().GetSuggestionByOrder(ctx, weekID, db.lastNumbers[weekID])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return *saved
+}
+
+func testVote(weekID general.WeekID, author string, id suggestion.OrderedID, preference uint) vote.Vote {
+	return vote.Vote{
+		VoteID:              vote.ID(author + string(rune('0'+id))),
+		SuggestionOrderedID: id,
+		Author:              author,
+		Preference:          preference,
+		WeekID:              weekID,
+	}
+}
+
+func TestGivenTheSameMovieTwiceInAWeekSaveRefusesIt(t *testing.T) {
+	db := testDatabase()
+	week := general.WeekID{IsoYear: 2021, IsoWeek: 14}
+	testSuggestion(t, db, week, "Shrek")
+
+	duplicate, _ := suggestion.NewSuggestion(week, "noah", general.MovieFromString("shrek"))
+	if err := db.Suggestions().Save(ctx, *duplicate); !errors.Is(err, suggestion.ErrDuplicateMovie) {
+		t.Fail()
+	}
+}
+
+func TestGivenTheSameMovieInAnotherWeekSaveAcceptsIt(t *testing.T) {
+	db := testDatabase()
+	testSuggestion(t, db, general.WeekID{IsoYear: 2021, IsoWeek: 14}, "Shrek")
+
+	other, _ := suggestion.NewSuggestion(general.WeekID{IsoYear: 2021, IsoWeek: 15}, "noah", general.MovieFromString("Shrek"))
+	if err := db.Suggestions().Save(ctx, *other); err != nil {
+		t.Fail()
+	}
+}
+
+func TestGivenARemovedSuggestionItsVotesAreRemoved(t *testing.T) {
+	db := testDatabase()
+	week := general.WeekID{IsoYear: 2021, IsoWeek: 14}
+	shrek := testSuggestion(t, db, week, "Shrek")
+	pooh := testSuggestion(t, db, week, "Winnie the Pooh")
+
+	db.Votes().BulkSaveVotes(ctx, "noah", week, []vote.Vote{
+		testVote(week, "noah", shrek.Order, 1),
+		testVote(week, "noah", pooh.Order, 2),
+	})
+
+	if err := db.Suggestions().Remove(ctx, shrek); err != nil {
+		t.Fatal(err)
+	}
+
+	ballots, _ := db.Votes().Ballots(ctx, week)
+	if len(ballots) != 1 || len(ballots[0].Ranking) != 1 || ballots[0].Ranking[0] != pooh.Order {
+		t.Fail()
+	}
+}
+
+func TestGivenAVoteForAnUnknownSuggestionNoVotesAreSaved(t *testing.T) {
+	db := testDatabase()
+	week := general.WeekID{IsoYear: 2021, IsoWeek: 14}
+	shrek := testSuggestion(t, db, week, "Shrek")
+
+	db.Votes().BulkSaveVotes(ctx, "noah", week, []vote.Vote{testVote(week, "noah", shrek.Order, 1)})
+	results, err := db.Votes().BulkSaveVotes(ctx, "noah", week, []vote.Vote{
+		testVote(week, "noah", 42, 1),
+		testVote(week, "noah", shrek.Order, 2),
+	})
+	if err != nil || len(results) != 2 {
+		t.Fatal(err)
+	}
+
+	ballots, _ := db.Votes().Ballots(ctx, week)
+	if len(ballots) != 1 || len(ballots[0].Ranking) != 1 || ballots[0].Ranking[0] != shrek.Order {
+		t.Fail()
+	}
+}
+
+func TestGivenSeveralVotersBallotReturnsOnlyTheAuthors(t *testing.T) {
+	db := testDatabase()
+	week := general.WeekID{IsoYear: 2021, IsoWeek: 14}
+	shrek := testSuggestion(t, db, week, "Shrek")
+	pooh := testSuggestion(t, db, week, "Winnie the Pooh")
+
+	db.Votes().BulkSaveVotes(ctx, "noah", week, []vote.Vote{
+		testVote(week, "noah", pooh.Order, 1),
+		testVote(week, "noah", shrek.Order, 2),
+	})
+	db.Votes().BulkSaveVotes(ctx, "liam", week, []vote.Vote{testVote(week, "liam", shrek.Order, 1)})
+
+	ballot, err := db.Votes().Ballot(ctx, "noah", week)
+	if err != nil || ballot == nil || len(ballot.Ranking) != 2 || ballot.Ranking[0] != pooh.Order {
+		t.Fail()
+	}
+
+	if none, err := db.Votes().Ballot(ctx, "oliver", week); err != nil || none != nil {
+		t.Fail()
+	}
+}
+
+func TestGivenARemovedSuggestionSavingItAsTheWinnerFails(t *testing.T) {
+	db := testDatabase()
+	week := general.WeekID{IsoYear: 2021, IsoWeek: 14}
+	shrek := testSuggestion(t, db, week, "Shrek")
+
+	if err := db.Suggestions().Remove(ctx, shrek); err != nil {
+		t.Fatal(err)
+	}
+
+	winner := vote.Candidate{ID: shrek.Order, Movie: shrek.Movie, Author: shrek.Author}
+	err := db.Votes().SaveWinner(ctx, week, winner, "irv", time.Date(2021, 4, 9, 19, 0, 0, 0, time.UTC))
+
+	if !errors.Is(err, suggestion.ErrUnknownSuggestion) {
+		t.Fail()
+	}
+}
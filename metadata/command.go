@@ -0,0 +1,164 @@
+package metadata
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/auth"
+	"github.com/urfave/cli/v2"
+)
+
+// maxSearchResults keeps searches for common titles readable.
+const maxSearchResults = 10
+
+func Command() *cli.Command {
+	description := `Search the movie catalog:
+    mov movies search "Dune"
+    mov movies search "Dune (2021)"
+
+Import a dataset into the catalog:
+    mov movies import [--format imdb|tmdb] [--ratings title.ratings.tsv.gz] title.basics.tsv.gz
+
+	imdb  title.basics.tsv from https://datasets.imdbws.com, ratings are read from title.ratings.tsv when given
+	tmdb  TMDb movie details as a JSON array or one object per line
+
+	Files ending in .gz are decompressed. Only admins may import datasets, and only from the command line since the file is read from the bot's host.
+`
+
+	return &cli.Command{
+		Name:        "movies",
+		Aliases:     []string{"m"},
+		Usage:       "searches and imports the movie catalog",
+		Description: description,
+		Subcommands: []*cli.Command{
+			{
+				Name:      "search",
+				Aliases:   []string{"s"},
+				Usage:     "Finds movies by title",
+				ArgsUsage: "<title>",
+				Action:    searchAction,
+			},
+			{
+				Name:      "import",
+				Usage:     "Imports a dataset into the catalog",
+				ArgsUsage: "<file>",
+				// Reads any path on the host, the bot doesn't run hidden
+				// commands
+				Hidden: true,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: "dataset format, imdb or tmdb",
+						Value: IMDb,
+					},
+					&cli.StringFlag{
+						Name:  "ratings",
+						Usage: "IMDb title.ratings.tsv to read ratings from",
+					},
+				},
+				Action: importAction,
+			},
+		},
+	}
+}
+
+func searchAction(c *cli.Context) error {
+	movieRepository := c.App.Metadata["movies"].(Store)
+
+	if c.NArg() < 1 {
+		_, writeErr := c.App.Writer.Write([]byte("Movie title not provided as argument.\n"))
+		return writeErr
+	}
+
+	records, err := movieRepository.Search(c.Context, c.Args().First())
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to search the movie catalog.\n"))
+		return err
+	}
+
+	if len(records) == 0 {
+		_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("No movies titled \"%s\" are in the catalog.\n", c.Args().First())))
+		return writeErr
+	}
+
+	_, writeErr := c.App.Writer.Write([]byte(FormatRecords(records)))
+	return writeErr
+}
+
+// FormatRecords lists the records with their ids, best known first.
+func FormatRecords(records []Record) string {
+	var outputBuffer strings.Builder
+
+	outputBuffer.WriteString(fmt.Sprintf("%-13s%-33.32s%s\n", "ID", "Movie", "Details"))
+	for i, record := range records {
+		if i == maxSearchResults {
+			outputBuffer.WriteString(fmt.Sprintf("and %d more, add the year to narrow it down.\n", len(records)-i))
+			break
+		}
+
+		outputBuffer.WriteString(fmt.Sprintf("%-13s%-33.32s%s\n", record.ID, record.String(), record.Details()))
+	}
+
+	return outputBuffer.String()
+}
+
+func importAction(c *cli.Context) error {
+	if allowed, err := auth.Require(c, auth.ManageDatabase); !allowed {
+		return err
+	}
+
+	movieRepository := c.App.Metadata["movies"].(Store)
+
+	if c.NArg() < 1 {
+		_, writeErr := c.App.Writer.Write([]byte("Dataset file not provided as argument.\n"))
+		return writeErr
+	}
+
+	records, err := readDataset(c.String("format"), c.Args().First(), c.String("ratings"))
+	if err != nil {
+		_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("Unable to read the dataset: %v\n", err)))
+		return writeErr
+	}
+
+	imported, err := movieRepository.Import(c.Context, records)
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to import the dataset.\n"))
+		return err
+	}
+
+	_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("Imported %d movies.\n", imported)))
+	return writeErr
+}
+
+func readDataset(format string, path string, ratingsPath string) ([]Record, error) {
+	file, err := OpenDataset(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch format {
+	case IMDb:
+		var ratings io.Reader
+		if ratingsPath != "" {
+			ratingsFile, err := OpenDataset(ratingsPath)
+			if err != nil {
+				return nil, err
+			}
+			defer ratingsFile.Close()
+
+			ratings = ratingsFile
+		}
+
+		return ReadIMDb(file, ratings)
+	case TMDb:
+		if ratingsPath != "" {
+			return nil, fmt.Errorf("--ratings only applies to the %s format", IMDb)
+		}
+
+		return ReadTMDb(file)
+	default:
+		return nil, fmt.Errorf("unknown format \"%s\", try %s or %s", format, IMDb, TMDb)
+	}
+}
@@ -0,0 +1,28 @@
+package metadata_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/movtest"
+)
+
+func TestGivenAnImportedDatasetSuggestionsAreResolved(t *testing.T) {
+	h := movtest.New(t)
+	h.Config.Admins = []string{"olivia"}
+
+	dataset := filepath.Join(t.TempDir(), "movies.json")
+	if err := ioutil.WriteFile(dataset, []byte(`[{"id": 438631, "title": "Dune", "release_date": "2021-09-15", "runtime": 155}]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	imported := h.MustRun("olivia", "movies import --format tmdb "+dataset)
+	h.MustRun("liam", "suggestions add dune")
+	output := h.MustRun("liam", "suggestions list")
+
+	if !strings.Contains(imported, "Imported 1 movies.") || !strings.Contains(output, "1   Dune (2021)") || !strings.Contains(output, "2h 35m") {
+		t.Fail()
+	}
+}
@@ -0,0 +1,252 @@
+package metadata
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dataset formats that can be imported.
+const (
+	// IMDb is the title.basics.tsv dataset from https://datasets.imdbws.com,
+	// optionally with title.ratings.tsv.
+	IMDb = "imdb"
+	// TMDb is a JSON array, or one JSON object per line, of TMDb movie
+	// details as the API returns them.
+	TMDb = "tmdb"
+)
+
+// imdbNull is how IMDb datasets write a missing value.
+const imdbNull = `\N`
+
+// imdbTitleTypes are the kinds of IMDb titles worth suggesting.
+var imdbTitleTypes = map[string]bool{
+	"movie":   true,
+	"tvMovie": true,
+}
+
+// OpenDataset opens a dataset file, decompressing it when the name ends in
+// .gz like the IMDb downloads do.
+func OpenDataset(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasSuffix(path, ".gz") {
+		return file, nil
+	}
+
+	decompressed, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("unable to decompress %s: %w", path, err)
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{decompressed, file}, nil
+}
+
+// ReadIMDb reads the movies of title.basics.tsv. Ratings are read from
+// title.ratings.tsv when ratings isn't nil.
+func ReadIMDb(basics io.Reader, ratings io.Reader) ([]Record, error) {
+	type rating struct {
+		average float64
+		votes   int
+	}
+
+	ratingsByID := map[string]rating{}
+	if ratings != nil {
+		err := readTSV(ratings, []string{"tconst", "averageRating", "numVotes"}, func(row map[string]string) error {
+			average, _ := strconv.ParseFloat(row["averageRating"], 64)
+			votes, _ := strconv.Atoi(row["numVotes"])
+			ratingsByID[row["tconst"]] = rating{average, votes}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("ratings: %w", err)
+		}
+	}
+
+	var records []Record
+	columns := []string{"tconst", "titleType", "primaryTitle", "startYear", "runtimeMinutes", "genres"}
+	err := readTSV(basics, columns, func(row map[string]string) error {
+		if !imdbTitleTypes[row["titleType"]] {
+			return nil
+		}
+
+		record := Record{
+			ID:    row["tconst"],
+			Title: row["primaryTitle"],
+		}
+
+		if row["startYear"] != imdbNull {
+			record.Year, _ = strconv.Atoi(row["startYear"])
+		}
+
+		if row["runtimeMinutes"] != imdbNull {
+			minutes, _ := strconv.Atoi(row["runtimeMinutes"])
+			record.Runtime = time.Duration(minutes) * time.Minute
+		}
+
+		if row["genres"] != imdbNull && row["genres"] != "" {
+			record.Genres = strings.Split(row["genres"], ",")
+		}
+
+		if r, rated := ratingsByID[record.ID]; rated {
+			record.Rating = r.average
+			record.Votes = r.votes
+		}
+
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("basics: %w", err)
+	}
+
+	return records, nil
+}
+
+// readTSV calls row for each line after the header with the named columns.
+// IMDb doesn't quote its fields, so lines are split on tabs alone.
+func readTSV(r io.Reader, columns []string, row func(map[string]string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("missing header")
+	}
+
+	header := map[string]int{}
+	for i, name := range strings.Split(scanner.Text(), "\t") {
+		header[name] = i
+	}
+
+	for _, column := range columns {
+		if _, exists := header[column]; !exists {
+			return fmt.Errorf("missing column \"%s\"", column)
+		}
+	}
+
+	line := 1
+	for scanner.Scan() {
+		line++
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < len(header) {
+			return fmt.Errorf("line %d has %d of %d columns", line, len(fields), len(header))
+		}
+
+		values := make(map[string]string, len(columns))
+		for _, column := range columns {
+			values[column] = fields[header[column]]
+		}
+
+		if err := row(values); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+type tmdbMovie struct {
+	ID          int64   `json:"id"`
+	Title       string  `json:"title"`
+	ReleaseDate string  `json:"release_date"`
+	Runtime     int     `json:"runtime"`
+	VoteAverage float64 `json:"vote_average"`
+	VoteCount   int     `json:"vote_count"`
+	Genres      []struct {
+		Name string `json:"name"`
+	} `json:"genres"`
+}
+
+// ReadTMDb reads TMDb movie details, either as a JSON array or as one object
+// per line.
+func ReadTMDb(r io.Reader) ([]Record, error) {
+	reader := bufio.NewReader(r)
+	decoder := json.NewDecoder(reader)
+
+	var movies []tmdbMovie
+	first, err := firstNonSpace(reader)
+	switch {
+	case err == io.EOF:
+		return nil, nil
+	case err != nil:
+		return nil, err
+	case first == '[':
+		if err := decoder.Decode(&movies); err != nil {
+			return nil, err
+		}
+	default:
+		for {
+			var movie tmdbMovie
+			err := decoder.Decode(&movie)
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			movies = append(movies, movie)
+		}
+	}
+
+	records := make([]Record, 0, len(movies))
+	for _, movie := range movies {
+		if movie.Title == "" {
+			continue
+		}
+
+		record := Record{
+			ID:      "tmdb:" + strconv.FormatInt(movie.ID, 10),
+			Title:   movie.Title,
+			Runtime: time.Duration(movie.Runtime) * time.Minute,
+			Rating:  movie.VoteAverage,
+			Votes:   movie.VoteCount,
+		}
+
+		if len(movie.ReleaseDate) >= 4 {
+			record.Year, _ = strconv.Atoi(movie.ReleaseDate[:4])
+		}
+
+		for _, genre := range movie.Genres {
+			record.Genres = append(record.Genres, genre.Name)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// firstNonSpace peeks at the first character that isn't white space.
+func firstNonSpace(reader *bufio.Reader) (byte, error) {
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+
+		if !strings.ContainsRune(" \t\r\n", rune(b[0])) {
+			return b[0], nil
+		}
+
+		reader.ReadByte()
+	}
+}
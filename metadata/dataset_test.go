@@ -0,0 +1,60 @@
+package metadata
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBasics = "tconst\ttitleType\tprimaryTitle\toriginalTitle\tisAdult\tstartYear\tendYear\truntimeMinutes\tgenres\n" +
+	"tt0087182\tmovie\tDune\tDune\t0\t1984\t\\N\t137\tAction,Adventure,Sci-Fi\n" +
+	"tt0142032\ttvMiniSeries\tDune\tDune\t0\t2000\t2000\t265\tAdventure,Drama\n" +
+	"tt1160419\tmovie\tDune\tDune\t0\t2021\t\\N\t\\N\t\\N\n"
+
+const testRatings = "tconst\taverageRating\tnumVotes\n" +
+	"tt0087182\t6.3\t155000\n"
+
+func TestGivenIMDbDatasetsOnlyMoviesAreRead(t *testing.T) {
+	records, err := ReadIMDb(strings.NewReader(testBasics), strings.NewReader(testRatings))
+	if err != nil || len(records) != 2 {
+		t.Fatal(err, records)
+	}
+
+	dune := records[0]
+	if dune.ID != "tt0087182" || dune.Year != 1984 || dune.Runtime != 137*time.Minute || len(dune.Genres) != 3 || dune.Rating != 6.3 || dune.Votes != 155000 {
+		t.Fail()
+	}
+
+	if records[1].Runtime != 0 || records[1].Genres != nil || records[1].Rating != 0 {
+		t.Fail()
+	}
+}
+
+func TestGivenAnIMDbDatasetWithoutAColumnReadingFails(t *testing.T) {
+	if _, err := ReadIMDb(strings.NewReader("tconst\tprimaryTitle\n"), nil); err == nil {
+		t.Fail()
+	}
+}
+
+func TestGivenATMDbArrayTheMoviesAreRead(t *testing.T) {
+	dataset := `[{"id": 438631, "title": "Dune", "release_date": "2021-09-15", "runtime": 155,
+		"genres": [{"id": 878, "name": "Science Fiction"}], "vote_average": 7.8, "vote_count": 9000}]`
+
+	records, err := ReadTMDb(strings.NewReader(dataset))
+	if err != nil || len(records) != 1 {
+		t.Fatal(err)
+	}
+
+	if records[0].ID != "tmdb:438631" || records[0].Year != 2021 || records[0].Runtime != 155*time.Minute || records[0].Genres[0] != "Science Fiction" {
+		t.Fail()
+	}
+}
+
+func TestGivenTMDbLinesTheMoviesAreRead(t *testing.T) {
+	dataset := "{\"id\": 1, \"title\": \"Shrek\"}\n{\"id\": 2, \"title\": \"Shrek 2\"}\n"
+
+	records, err := ReadTMDb(strings.NewReader(dataset))
+	if err != nil || len(records) != 2 || records[1].Title != "Shrek 2" {
+		t.Fail()
+	}
+}
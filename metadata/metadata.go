@@ -0,0 +1,211 @@
+// Package metadata resolves movie titles to canonical records, so a
+// suggestion of "Dune" can tell the 1984 movie from the 2021 one and show its
+// year and runtime.
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrUnknownMovie is returned when no record has the id.
+var ErrUnknownMovie = errors.New("movie is not in the catalog")
+
+// Record is the canonical description of a movie.
+type Record struct {
+	// ID is unique across datasets, such as "tt1160419" for IMDb or
+	// "tmdb:438631" for TMDb.
+	ID      string
+	Title   string
+	Year    int
+	Runtime time.Duration
+	Genres  []string
+	// Rating is out of 10, 0 when unknown.
+	Rating float64
+	// Votes is how many people rated the movie. Better known movies are
+	// listed first when several match.
+	Votes int
+}
+
+// String names the movie with its year, e.g. "Dune (2021)".
+func (record Record) String() string {
+	if record.Year == 0 {
+		return record.Title
+	}
+
+	return fmt.Sprintf("%s (%d)", record.Title, record.Year)
+}
+
+// Details lists what is known of the movie besides its title, e.g.
+// "2h 35m, Action/Adventure, 8.0/10".
+func (record Record) Details() string {
+	var details []string
+	if record.Runtime > 0 {
+		details = append(details, general.FormatDuration(record.Runtime))
+	}
+
+	if len(record.Genres) > 0 {
+		details = append(details, strings.Join(record.Genres, "/"))
+	}
+
+	if record.Rating > 0 {
+		details = append(details, fmt.Sprintf("%.1f/10", record.Rating))
+	}
+
+	return strings.Join(details, ", ")
+}
+
+// TitledAs reports whether the query names the record as it is titled,
+// ignoring case and a year in parentheses that matches the record's.
+func (record Record) TitledAs(query string) bool {
+	title, year := ParseQuery(query)
+	if year != 0 && year != record.Year {
+		return false
+	}
+
+	return strings.EqualFold(norm.NFC.String(title.String()), norm.NFC.String(record.Title))
+}
+
+// MetadataProvider finds the records of movies. The catalog imported from a
+// local dataset is one, a provider calling a web API could be another.
+type MetadataProvider interface {
+	// Search returns the records whose TitleKey matches the query's, those
+	// TitledAs the query first, then the best known. A year in parentheses,
+	// as in "Dune (2021)", narrows the search.
+	Search(ctx context.Context, title string) ([]Record, error)
+	// Lookup returns ErrUnknownMovie when no record has the id.
+	Lookup(ctx context.Context, id string) (*Record, error)
+}
+
+// Store is a MetadataProvider that datasets can be imported into.
+type Store interface {
+	MetadataProvider
+	// Import adds the records, replacing those with the same id, and returns
+	// how many were imported.
+	Import(ctx context.Context, records []Record) (int, error)
+}
+
+// TitleKey is what titles are searched by: their letters and digits in
+// lowercase, without accents. "Spider-Man" and "spiderman" share a key, "Done"
+// and "Dune" don't.
+func TitleKey(title string) string {
+	var key strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(title)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			key.WriteRune(r)
+		}
+	}
+
+	return key.String()
+}
+
+var yearSuffix = regexp.MustCompile(`^(.*\S)\s*\((\d{4})\)$`)
+
+// ParseQuery splits a trailing year in parentheses from a title. The year is
+// 0 when there is none.
+func ParseQuery(query string) (general.Movie, int) {
+	query = strings.TrimSpace(query)
+
+	match := yearSuffix.FindStringSubmatch(query)
+	if match == nil {
+		return general.MovieFromString(query), 0
+	}
+
+	year, _ := strconv.Atoi(match[2])
+	return general.MovieFromString(match[1]), year
+}
+
+// Catalog is a MetadataProvider held in memory.
+type Catalog struct {
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+func NewCatalog(records ...Record) *Catalog {
+	catalog := &Catalog{records: map[string]Record{}}
+	catalog.Import(context.Background(), records)
+
+	return catalog
+}
+
+func (catalog *Catalog) Search(ctx context.Context, query string) ([]Record, error) {
+	title, year := ParseQuery(query)
+
+	catalog.mu.Lock()
+	defer catalog.mu.Unlock()
+
+	var found []Record
+	for _, record := range catalog.records {
+		if TitleKey(record.Title) != TitleKey(title.String()) {
+			continue
+		}
+
+		if year != 0 && record.Year != year {
+			continue
+		}
+
+		found = append(found, record)
+	}
+
+	sortRecords(found)
+	exactFirst(found, query)
+
+	return found, nil
+}
+
+func (catalog *Catalog) Lookup(ctx context.Context, id string) (*Record, error) {
+	catalog.mu.Lock()
+	defer catalog.mu.Unlock()
+
+	record, exists := catalog.records[id]
+	if !exists {
+		return nil, ErrUnknownMovie
+	}
+
+	return &record, nil
+}
+
+func (catalog *Catalog) Import(ctx context.Context, records []Record) (int, error) {
+	catalog.mu.Lock()
+	defer catalog.mu.Unlock()
+
+	for _, record := range records {
+		catalog.records[record.ID] = record
+	}
+
+	return len(records), nil
+}
+
+// exactFirst moves the records TitledAs the query ahead of the rest, keeping
+// their order otherwise.
+func exactFirst(records []Record, query string) {
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].TitledAs(query) && !records[j].TitledAs(query)
+	})
+}
+
+// sortRecords orders the best known movies first, then the newest.
+func sortRecords(records []Record) {
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Votes != records[j].Votes {
+			return records[i].Votes > records[j].Votes
+		}
+
+		if records[i].Year != records[j].Year {
+			return records[i].Year > records[j].Year
+		}
+
+		return records[i].ID < records[j].ID
+	})
+}
@@ -0,0 +1,63 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+)
+
+func testCatalog() *Catalog {
+	return NewCatalog(
+		Record{ID: "tt0087182", Title: "Dune", Year: 1984, Votes: 155000},
+		Record{ID: "tt1160419", Title: "Dune", Year: 2021, Votes: 600000},
+		Record{ID: "tt0126029", Title: "Shrek", Year: 2001, Votes: 700000},
+	)
+}
+
+func TestGivenSeveralMatchesSearchListsTheBestKnownFirst(t *testing.T) {
+	records, err := testCatalog().Search(context.Background(), "dune")
+
+	if err != nil || len(records) != 2 || records[0].Year != 2021 {
+		t.Fail()
+	}
+}
+
+func TestGivenAYearSearchOnlyMatchesThatYear(t *testing.T) {
+	records, err := testCatalog().Search(context.Background(), "Dune (1984)")
+
+	if err != nil || len(records) != 1 || records[0].ID != "tt0087182" {
+		t.Fail()
+	}
+}
+
+func TestGivenAnUnknownIDLookupFails(t *testing.T) {
+	if _, err := testCatalog().Lookup(context.Background(), "tt0000000"); err != ErrUnknownMovie {
+		t.Fail()
+	}
+}
+
+func TestGivenARecordItsNameIncludesTheYear(t *testing.T) {
+	if (Record{Title: "Dune", Year: 2021}).String() != "Dune (2021)" || (Record{Title: "Dune"}).String() != "Dune" {
+		t.Fail()
+	}
+}
+
+func TestGivenTitlesDifferingInVowelsSearchDoesNotMatchThem(t *testing.T) {
+	records, err := testCatalog().Search(context.Background(), "Done")
+
+	if err != nil || len(records) != 0 {
+		t.Fail()
+	}
+}
+
+func TestGivenAnExactTitleSearchListsItFirst(t *testing.T) {
+	catalog := NewCatalog(
+		Record{ID: "tt0145487", Title: "Spider-Man", Year: 2002, Votes: 800000},
+		Record{ID: "tt0000001", Title: "Spiderman", Year: 1990, Votes: 10},
+	)
+
+	records, err := catalog.Search(context.Background(), "spiderman")
+
+	if err != nil || len(records) != 2 || records[0].ID != "tt0000001" || records[1].TitledAs("spiderman") {
+		t.Fail()
+	}
+}
@@ -0,0 +1,132 @@
+package metadata
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+)
+
+// Repository is the SQL Store, for SQLite and PostgreSQL.
+type Repository struct {
+	session *storage.DB
+}
+
+func NewRepository(session *storage.DB) *Repository {
+	return &Repository{
+		session: session,
+	}
+}
+
+const recordColumns = `id, title, year, runtime, genres, rating, votes`
+
+func (context *Repository) Search(ctx context.Context, query string) ([]Record, error) {
+	title, year := ParseQuery(query)
+
+	rows, err := context.session.QueryContext(ctx, `
+		SELECT `+recordColumns+`
+		FROM movies
+		WHERE titleKey = ? AND (? = 0 OR year = ?)
+		ORDER BY votes DESC, year DESC, id ASC`,
+		TitleKey(title.String()), year, year)
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		record, err := scanRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, *record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+
+	exactFirst(records, query)
+
+	return records, nil
+}
+
+func (context *Repository) Lookup(ctx context.Context, id string) (*Record, error) {
+	row := context.session.QueryRowContext(ctx, `SELECT `+recordColumns+` FROM movies WHERE id = ?`, id)
+
+	record, err := scanRecord(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUnknownMovie
+	}
+
+	return record, err
+}
+
+// Import saves the records in a single transaction, so a dataset that fails
+// part way isn't half imported.
+func (context *Repository) Import(ctx context.Context, records []Record) (int, error) {
+	tx, err := context.session.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "")
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO movies (id, title, titleKey, year, runtime, genres, rating, votes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			title = excluded.title,
+			titleKey = excluded.titleKey,
+			year = excluded.year,
+			runtime = excluded.runtime,
+			genres = excluded.genres,
+			rating = excluded.rating,
+			votes = excluded.votes`)
+	if err != nil {
+		tx.Rollback()
+		return 0, errors.Wrap(err, "")
+	}
+	defer stmt.Close()
+
+	for _, record := range records {
+		_, err := stmt.ExecContext(ctx, record.ID, record.Title, TitleKey(record.Title),
+			record.Year, int64(record.Runtime/time.Minute), strings.Join(record.Genres, ","), record.Rating, record.Votes)
+		if err != nil {
+			tx.Rollback()
+			return 0, errors.Wrap(storage.Translate(err), record.ID)
+		}
+	}
+
+	return len(records), errors.Wrap(tx.Commit(), "")
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRecord(row scanner) (*Record, error) {
+	var record Record
+	var minutes int64
+	var genres string
+
+	err := row.Scan(&record.ID, &record.Title, &record.Year, &minutes, &genres, &record.Rating, &record.Votes)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+
+	record.Runtime = time.Duration(minutes) * time.Minute
+	if genres != "" {
+		record.Genres = strings.Split(genres, ",")
+	}
+
+	return &record, nil
+}
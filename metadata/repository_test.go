@@ -0,0 +1,50 @@
+package metadata_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/dbtest"
+	"github.com/fredlawl/200-colony-movie-night-bot/metadata"
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+)
+
+func TestGivenImportedRecordsSearchFindsThemByTitle(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, session *storage.DB) {
+		repository := metadata.NewRepository(session)
+		ctx := context.Background()
+
+		imported, err := repository.Import(ctx, []metadata.Record{
+			{ID: "tmdb:841", Title: "Dune", Year: 1984, Runtime: 137 * time.Minute, Genres: []string{"Science Fiction"}, Rating: 6.3, Votes: 3000},
+			{ID: "tmdb:438631", Title: "Dune", Year: 2021, Runtime: 155 * time.Minute, Genres: []string{"Science Fiction", "Adventure"}, Rating: 7.8, Votes: 9000},
+			{ID: "tmdb:13", Title: "Done", Year: 2010},
+		})
+		if err != nil || imported != 3 {
+			t.Fatal(err, imported)
+		}
+
+		// Importing again updates the record
+		if _, err := repository.Import(ctx, []metadata.Record{{ID: "tmdb:841", Title: "Dune", Year: 1984, Votes: 10000}}); err != nil {
+			t.Fatal(err)
+		}
+
+		records, err := repository.Search(ctx, "dune")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dune, err := repository.Lookup(ctx, "tmdb:438631")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, unknown := repository.Lookup(ctx, "tmdb:1")
+
+		if len(records) != 2 || records[0].ID != "tmdb:841" || records[1].ID != "tmdb:438631" ||
+			dune.Runtime != 155*time.Minute || len(dune.Genres) != 2 || dune.Genres[1] != "Adventure" || !errors.Is(unknown, metadata.ErrUnknownMovie) {
+			t.Fail()
+		}
+	})
+}
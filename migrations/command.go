@@ -0,0 +1,144 @@
+package migrations
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/auth"
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+func Command() *cli.Command {
+	description := `Show which migrations are applied:
+    mov db status
+
+Apply pending migrations:
+    mov db migrate
+
+Roll back the latest migration, from the command line only:
+    mov db rollback
+
+	Pending migrations are applied whenever mov starts unless auto-migrate is turned off. Only admins may migrate or roll back.
+`
+
+	return &cli.Command{
+		Name:        "db",
+		Usage:       "manages the database schema",
+		Description: description,
+		Subcommands: []*cli.Command{
+			{
+				Name:   "status",
+				Usage:  "Lists migrations and whether they are applied",
+				Action: statusAction,
+			},
+			{
+				Name:   "migrate",
+				Usage:  "Applies pending migrations",
+				Action: migrateAction,
+			},
+			{
+				Name:  "rollback",
+				Usage: "Rolls back the latest migration",
+				// Dropping tables is too destructive to offer in chat,
+				// the bot doesn't run hidden commands
+				Hidden: true,
+				Action: rollbackAction,
+			},
+		},
+	}
+}
+
+func statusAction(c *cli.Context) error {
+	migrator, err := commandMigrator(c)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	var outputBuffer strings.Builder
+
+	outputBuffer.WriteString(fmt.Sprintf("%-9s%-33.32s%s\n", "Version", "Name", "Applied"))
+	for _, status := range statuses {
+		applied := "pending"
+		if status.Applied {
+			applied = status.DateApplied.Format("2006-01-02 15:04")
+		}
+
+		outputBuffer.WriteString(fmt.Sprintf("%-9s%-33.32s%s\n",
+			fmt.Sprintf("%04d", status.Version),
+			status.Name,
+			applied))
+	}
+
+	_, writeErr := c.App.Writer.Write([]byte(outputBuffer.String()))
+	return writeErr
+}
+
+func migrateAction(c *cli.Context) error {
+	if allowed, err := auth.Require(c, auth.ManageDatabase); !allowed {
+		return err
+	}
+
+	migrator, err := commandMigrator(c)
+	if err != nil {
+		return err
+	}
+
+	migrated, migrateErr := migrator.Migrate()
+
+	var outputBuffer strings.Builder
+	for _, migration := range migrated {
+		outputBuffer.WriteString(fmt.Sprintf("Applied %s\n", migration))
+	}
+
+	if len(migrated) == 0 && migrateErr == nil {
+		outputBuffer.WriteString("The database is up to date.\n")
+	}
+
+	if _, writeErr := c.App.Writer.Write([]byte(outputBuffer.String())); writeErr != nil {
+		return writeErr
+	}
+
+	return migrateErr
+}
+
+func rollbackAction(c *cli.Context) error {
+	if allowed, err := auth.Require(c, auth.ManageDatabase); !allowed {
+		return err
+	}
+
+	migrator, err := commandMigrator(c)
+	if err != nil {
+		return err
+	}
+
+	migration, rollbackErr := migrator.Rollback()
+	if rollbackErr != nil {
+		return rollbackErr
+	}
+
+	output := "No migrations are applied.\n"
+	if migration != nil {
+		output = fmt.Sprintf("Rolled back %s\n", migration)
+	}
+
+	_, writeErr := c.App.Writer.Write([]byte(output))
+	return writeErr
+}
+
+// commandMigrator migrates the database the command runs against. Commands
+// running over in-memory stores have none.
+func commandMigrator(c *cli.Context) (*Migrator, error) {
+	dbSession, hasDB := c.App.Metadata["dbSession"].(*storage.DB)
+	if !hasDB {
+		return nil, errors.New("no database is open")
+	}
+
+	return NewMigrator(dbSession)
+}
@@ -0,0 +1,257 @@
+// Package migrations keeps the database schema in versioned migrations
+// embedded in the binary. Each driver has its own directory, and each
+// version an up file and a down file named like
+// sql/sqlite3/0002_winners.up.sql.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+)
+
+//go:embed sql/sqlite3/*.sql sql/postgres/*.sql
+var files embed.FS
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d %s", m.Version, m.Name)
+}
+
+// Status is a migration and whether it is applied.
+type Status struct {
+	Migration
+	Applied     bool
+	DateApplied time.Time
+}
+
+// All reads the embedded migrations of the driver in version order.
+func All(driver string) ([]Migration, error) {
+	return load(files, "sql/"+driver)
+}
+
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		name := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimSuffix(name, "."+direction+".sql"), "_", 2)
+		version, versionErr := strconv.Atoi(parts[0])
+		if versionErr != nil || len(parts) != 2 {
+			return nil, fmt.Errorf("migration %s isn't named like 0001_name.up.sql", name)
+		}
+
+		contents, readErr := fs.ReadFile(fsys, dir+"/"+name)
+		if readErr != nil {
+			return nil, readErr
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: parts[1]}
+			byVersion[version] = migration
+		}
+
+		if direction == "up" {
+			migration.Up = string(contents)
+		} else {
+			migration.Down = string(contents)
+		}
+	}
+
+	var migrations []Migration
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %s needs both an up and a down file", migration)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Migrator applies and rolls back migrations, recording the applied
+// versions in schema_migrations.
+type Migrator struct {
+	session    *storage.DB
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator for the embedded migrations of the
+// database's driver.
+func NewMigrator(session *storage.DB) (*Migrator, error) {
+	migrations, err := All(session.Driver)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		session:    session,
+		migrations: migrations,
+	}, nil
+}
+
+func (m *Migrator) ensureVersionTable() error {
+	timestamp := "DATETIME"
+	if m.session.Driver == storage.Postgres {
+		timestamp = "TIMESTAMPTZ"
+	}
+
+	_, err := m.session.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER NOT NULL PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			dateApplied ` + timestamp + ` NOT NULL DEFAULT current_timestamp
+		)`)
+
+	return errors.Wrap(err, "")
+}
+
+func (m *Migrator) applied() (map[int]time.Time, error) {
+	if err := m.ensureVersionTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.session.Query(`SELECT version, dateApplied FROM schema_migrations`)
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var dateApplied time.Time
+		if err := rows.Scan(&version, &dateApplied); err != nil {
+			return nil, errors.Wrap(err, "")
+		}
+		applied[version] = dateApplied
+	}
+
+	return applied, errors.Wrap(rows.Err(), "")
+}
+
+// Status lists every migration and whether it is applied.
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(m.migrations))
+	for i, migration := range m.migrations {
+		dateApplied, isApplied := applied[migration.Version]
+		statuses[i] = Status{
+			Migration:   migration,
+			Applied:     isApplied,
+			DateApplied: dateApplied,
+		}
+	}
+
+	return statuses, nil
+}
+
+// Migrate applies every pending migration in order and returns the ones it
+// applied. Each migration runs in its own transaction.
+func (m *Migrator) Migrate() ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var migrated []Migration
+	for _, migration := range m.migrations {
+		if _, isApplied := applied[migration.Version]; isApplied {
+			continue
+		}
+
+		if err := m.run(migration.Up, `INSERT INTO schema_migrations (version, name) VALUES (?, ?)`,
+			migration.Version, migration.Name); err != nil {
+			return migrated, errors.Wrapf(err, "applying migration %s", migration)
+		}
+
+		migrated = append(migrated, migration)
+	}
+
+	return migrated, nil
+}
+
+// Rollback reverts the most recently applied migration. It returns nil when
+// nothing is applied.
+func (m *Migrator) Rollback() (*Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, isApplied := applied[migration.Version]; !isApplied {
+			continue
+		}
+
+		if err := m.run(migration.Down, `DELETE FROM schema_migrations WHERE version = ?`,
+			migration.Version); err != nil {
+			return nil, errors.Wrapf(err, "rolling back migration %s", migration)
+		}
+
+		return &migration, nil
+	}
+
+	return nil, nil
+}
+
+// run executes a migration script and records it in one transaction.
+func (m *Migrator) run(script string, record string, args ...interface{}) error {
+	tx, err := m.session.Begin()
+	if err != nil {
+		return err
+	}
+
+	// Scripts aren't written with placeholders, rebinding them would
+	// rewrite any ? in their string literals
+	if _, err := tx.Tx.Exec(script); err != nil {
+		tx.Rollback()
+		return storage.Translate(err)
+	}
+
+	if _, err := tx.Exec(record, args...); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,253 @@
+package migrations
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+)
+
+func newTestMigrator(t *testing.T) (*Migrator, *storage.DB) {
+	dbSession, err := storage.Open(storage.SQLite, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every connection to :memory: is a new database
+	dbSession.SetMaxOpenConns(1)
+	t.Cleanup(func() { dbSession.Close() })
+
+	migrator, err := NewMigrator(dbSession)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return migrator, dbSession
+}
+
+func tableExists(t *testing.T, dbSession *storage.DB, name string) bool {
+	var count int
+	err := dbSession.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = ?`, name).Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return count > 0
+}
+
+func columnExists(t *testing.T, dbSession *storage.DB, table string, name string) bool {
+	var count int
+	err := dbSession.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, name).Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return count > 0
+}
+
+func TestGivenTheEmbeddedMigrationsTheyAreInVersionOrder(t *testing.T) {
+	migrations, err := All(storage.SQLite)
+	if err != nil || len(migrations) == 0 {
+		t.Fatal(err)
+	}
+
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			t.Errorf("expected migration %d to be version %d, got %s", i, i+1, migration)
+		}
+	}
+}
+
+func TestGivenEachDriverTheyHaveTheSameMigrations(t *testing.T) {
+	sqlite, err := All(storage.SQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	postgres, err := All(storage.Postgres)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sqlite) != len(postgres) {
+		t.Fatalf("expected %d postgres migrations, got %d", len(sqlite), len(postgres))
+	}
+
+	for i := range sqlite {
+		if sqlite[i].String() != postgres[i].String() {
+			t.Errorf("expected migration %s, got %s", sqlite[i], postgres[i])
+		}
+	}
+}
+
+func TestGivenAnEmptyDatabaseMigrateAppliesEverything(t *testing.T) {
+	migrator, dbSession := newTestMigrator(t)
+
+	migrated, err := migrator.Migrate()
+	if err != nil || len(migrated) != len(migrator.migrations) {
+		t.Fatal(err)
+	}
+
+	if !tableExists(t, dbSession, "suggestions") || !tableExists(t, dbSession, "users") {
+		t.Fail()
+	}
+
+	migrated, err = migrator.Migrate()
+	if err != nil || len(migrated) != 0 {
+		t.Fail()
+	}
+}
+
+func TestGivenAnAppliedMigrationRollbackRevertsIt(t *testing.T) {
+	migrator, dbSession := newTestMigrator(t)
+	migrator.Migrate()
+
+	latest := migrator.migrations[len(migrator.migrations)-1]
+	rolledBack, err := migrator.Rollback()
+	if err != nil || rolledBack == nil || rolledBack.Version != latest.Version {
+		t.Fatal(err)
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil || statuses[len(statuses)-1].Applied || !statuses[0].Applied {
+		t.Fail()
+	}
+
+	if columnExists(t, dbSession, "winners", "movieID") || !columnExists(t, dbSession, "winners", "method") {
+		t.Fail()
+	}
+}
+
+func TestGivenEveryMigrationRolledBackTheyApplyAgain(t *testing.T) {
+	migrator, dbSession := newTestMigrator(t)
+	migrator.Migrate()
+
+	for range migrator.migrations {
+		if _, err := migrator.Rollback(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if rolledBack, err := migrator.Rollback(); err != nil || rolledBack != nil {
+		t.Fail()
+	}
+
+	if tableExists(t, dbSession, "suggestions") {
+		t.Fail()
+	}
+
+	if migrated, err := migrator.Migrate(); err != nil || len(migrated) != len(migrator.migrations) {
+		t.Fail()
+	}
+}
+
+func TestGivenAMigratedDatabaseTheSeedApplies(t *testing.T) {
+	migrator, dbSession := newTestMigrator(t)
+	if _, err := migrator.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	seed, err := ioutil.ReadFile("../seed.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := dbSession.Exec(string(seed)); err != nil {
+		t.Fatal(err)
+	}
+
+	var suggestions, votes int
+	if err := dbSession.QueryRow(`SELECT COUNT(*) FROM suggestions`).Scan(&suggestions); err != nil {
+		t.Fatal(err)
+	}
+	if err := dbSession.QueryRow(`SELECT COUNT(*) FROM votes`).Scan(&votes); err != nil {
+		t.Fatal(err)
+	}
+
+	if suggestions != 3 || votes != 17 {
+		t.Fail()
+	}
+}
+
+// recorder is a database/sql driver that records the statements it runs,
+// every query returns no rows.
+type recorder struct {
+	statements []string
+}
+
+func (r *recorder) Open(name string) (driver.Conn, error) { return r, nil }
+func (r *recorder) Close() error                          { return nil }
+func (r *recorder) Begin() (driver.Tx, error)             { return r, nil }
+func (r *recorder) Commit() error                         { return nil }
+func (r *recorder) Rollback() error                       { return nil }
+
+func (r *recorder) Prepare(query string) (driver.Stmt, error) {
+	return &recordedStmt{recorder: r, query: query}, nil
+}
+
+type recordedStmt struct {
+	recorder *recorder
+	query    string
+}
+
+func (s *recordedStmt) Close() error  { return nil }
+func (s *recordedStmt) NumInput() int { return -1 }
+
+func (s *recordedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.recorder.statements = append(s.recorder.statements, s.query)
+	return driver.RowsAffected(0), nil
+}
+
+func (s *recordedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return noRows{}, nil
+}
+
+type noRows struct{}
+
+func (noRows) Columns() []string              { return []string{"version", "dateApplied"} }
+func (noRows) Close() error                   { return nil }
+func (noRows) Next(dest []driver.Value) error { return io.EOF }
+
+func TestGivenPostgresMigrationScriptsTheyRunUnchanged(t *testing.T) {
+	recorded := &recorder{}
+	sql.Register("recorder", recorded)
+
+	session, err := sql.Open("recorder", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { session.Close() })
+
+	migrator, err := NewMigrator(storage.Wrap(session, storage.Postgres))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrated, err := migrator.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ran := map[string]bool{}
+	for _, statement := range recorded.statements {
+		ran[statement] = true
+	}
+
+	literal := false
+	for _, migration := range migrated {
+		if !ran[migration.Up] {
+			t.Errorf("expected migration %s to run unchanged", migration)
+		}
+
+		literal = literal || strings.Contains(migration.Up, "'?'")
+	}
+
+	// 0012 replaces '?' within a string literal
+	if !literal {
+		t.Fail()
+	}
+}
@@ -0,0 +1,39 @@
+package migrations_test
+
+import (
+	"testing"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/dbtest"
+	"github.com/fredlawl/200-colony-movie-night-bot/migrations"
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+)
+
+func TestGivenPostgresEveryMigrationAppliesAndRollsBack(t *testing.T) {
+	migrator, err := migrations.NewMigrator(dbtest.Empty(t, storage.Postgres))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := migrations.All(storage.Postgres)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if migrated, err := migrator.Migrate(); err != nil || len(migrated) != len(all) {
+		t.Fatalf("migrating: %+v", err)
+	}
+
+	for range all {
+		if _, err := migrator.Rollback(); err != nil {
+			t.Fatalf("rolling back: %+v", err)
+		}
+	}
+
+	if rolledBack, err := migrator.Rollback(); err != nil || rolledBack != nil {
+		t.Fail()
+	}
+
+	if migrated, err := migrator.Migrate(); err != nil || len(migrated) != len(all) {
+		t.Fatalf("migrating again: %+v", err)
+	}
+}
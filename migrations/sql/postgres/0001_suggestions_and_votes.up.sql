@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS suggestions (
+    id SERIAL PRIMARY KEY,
+    uuid VARCHAR(36) NOT NULL,
+    weekID INTEGER NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    movie VARCHAR(255) NOT NULL,
+    movieHash VARCHAR(255) NOT NULL,
+    dateAdded TIMESTAMPTZ NOT NULL DEFAULT current_timestamp
+);
+CREATE UNIQUE INDEX IF NOT EXISTS ix_suggestions_uuid ON suggestions(uuid);
+CREATE UNIQUE INDEX IF NOT EXISTS ix_suggestions_weekID_movieHash ON suggestions(weekID, movieHash);
+CREATE TABLE IF NOT EXISTS votes (
+    suggestionID INTEGER NOT NULL,
+    weekID INTEGER NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    preference INTEGER NOT NULL,
+    dateAdded TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+    PRIMARY KEY(weekID, author, suggestionID),
+    CONSTRAINT fk_votes_suggestionID FOREIGN KEY (suggestionID) REFERENCES suggestions(id) ON DELETE CASCADE
+);
+CREATE OR REPLACE VIEW vw_leaderboard
+AS
+SELECT
+    s.id AS suggestionID
+    , s.weekID
+    , s.movie
+    , v.preference
+    , COUNT(s.id) AS votes
+FROM suggestions s
+INNER JOIN votes v
+    ON v.suggestionID = s.id
+    AND v.weekID = s.weekID
+GROUP BY
+    s.id
+    , s.weekID
+    , s.movie
+    , v.preference
+ORDER BY v.preference ASC, movie ASC;
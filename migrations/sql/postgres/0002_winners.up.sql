@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS winners (
+    weekID INTEGER NOT NULL PRIMARY KEY,
+    suggestionID INTEGER NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    movie VARCHAR(255) NOT NULL,
+    method VARCHAR(32) NOT NULL,
+    dateDecided TIMESTAMPTZ NOT NULL DEFAULT current_timestamp
+);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS users (
+    name VARCHAR(255) NOT NULL PRIMARY KEY,
+    role VARCHAR(16) NOT NULL DEFAULT 'member',
+    dateAdded TIMESTAMPTZ NOT NULL DEFAULT current_timestamp
+);
@@ -0,0 +1,16 @@
+ALTER TABLE suggestions ADD COLUMN number INTEGER NOT NULL DEFAULT 0;
+UPDATE suggestions SET number = (
+    SELECT COUNT(*)
+    FROM suggestions earlier
+    WHERE earlier.weekID = suggestions.weekID
+        AND earlier.id <= suggestions.id
+);
+CREATE UNIQUE INDEX IF NOT EXISTS ix_suggestions_weekID_number ON suggestions(weekID, number);
+CREATE TABLE IF NOT EXISTS suggestion_numbers (
+    weekID INTEGER NOT NULL PRIMARY KEY,
+    lastNumber INTEGER NOT NULL
+);
+INSERT INTO suggestion_numbers (weekID, lastNumber)
+SELECT weekID, MAX(number)
+FROM suggestions
+GROUP BY weekID;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS movies (
+    id VARCHAR(32) NOT NULL PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    titleHash VARCHAR(255) NOT NULL,
+    year INTEGER NOT NULL DEFAULT 0,
+    runtime INTEGER NOT NULL DEFAULT 0,
+    genres VARCHAR(255) NOT NULL DEFAULT '',
+    rating DOUBLE PRECISION NOT NULL DEFAULT 0,
+    votes INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS ix_movies_titleHash ON movies(titleHash);
+ALTER TABLE suggestions ADD COLUMN movieID VARCHAR(32);
@@ -0,0 +1,2 @@
+ALTER TABLE suggestions DROP COLUMN link;
+ALTER TABLE suggestions DROP COLUMN pitch;
@@ -0,0 +1,2 @@
+ALTER TABLE suggestions ADD COLUMN pitch TEXT NOT NULL DEFAULT '';
+ALTER TABLE suggestions ADD COLUMN link VARCHAR(2048) NOT NULL DEFAULT '';
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS suggestion_renames (
+    id SERIAL PRIMARY KEY,
+    suggestionID INTEGER NOT NULL,
+    movie VARCHAR(255) NOT NULL,
+    movieID VARCHAR(32),
+    renamedBy VARCHAR(255) NOT NULL,
+    dateRenamed TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+    CONSTRAINT fk_suggestion_renames_suggestionID FOREIGN KEY (suggestionID) REFERENCES suggestions(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS ix_suggestion_renames_suggestionID ON suggestion_renames(suggestionID);
@@ -0,0 +1,5 @@
+-- Import the dataset again to fill in the hashes
+ALTER TABLE movies ADD COLUMN titleHash VARCHAR(255) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS ix_movies_titleHash ON movies(titleHash);
+DROP INDEX IF EXISTS ix_movies_titleKey;
+ALTER TABLE movies DROP COLUMN titleKey;
@@ -0,0 +1 @@
+ALTER TABLE winners DROP COLUMN movieID;
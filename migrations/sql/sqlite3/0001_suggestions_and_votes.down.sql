@@ -0,0 +1,3 @@
+DROP VIEW IF EXISTS vw_leaderboard;
+DROP TABLE IF EXISTS votes;
+DROP TABLE IF EXISTS suggestions;
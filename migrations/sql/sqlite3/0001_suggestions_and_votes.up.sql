@@ -35,4 +35,4 @@ GROUP BY
     , s.weekID
     , s.movie
     , v.preference
-ORDER BY v.preference ASC, movie ASC;
\ No newline at end of file
+ORDER BY v.preference ASC, movie ASC;
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS period_states (
+    weekID INTEGER NOT NULL PRIMARY KEY,
+    period INTEGER NOT NULL,
+    dateChanged DATETIME NOT NULL DEFAULT current_timestamp
+);
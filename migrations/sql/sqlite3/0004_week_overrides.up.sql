@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS week_overrides (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    weekID INTEGER NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    period INTEGER NOT NULL,
+    amount INTEGER NOT NULL DEFAULT 0,
+    effectiveAt DATETIME NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    dateAdded DATETIME NOT NULL DEFAULT current_timestamp
+);
+CREATE INDEX IF NOT EXISTS ix_week_overrides_weekID ON week_overrides(weekID);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS suggestion_numbers;
+DROP INDEX IF EXISTS ix_suggestions_weekID_number;
+ALTER TABLE suggestions DROP COLUMN number;
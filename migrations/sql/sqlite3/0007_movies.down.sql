@@ -0,0 +1,2 @@
+ALTER TABLE suggestions DROP COLUMN movieID;
+DROP TABLE IF EXISTS movies;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS suggestion_exceptions (
+    weekID INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    remaining INTEGER NOT NULL DEFAULT 0,
+    grantedBy VARCHAR(255) NOT NULL,
+    dateGranted DATETIME NOT NULL DEFAULT current_timestamp,
+    PRIMARY KEY(weekID, name)
+);
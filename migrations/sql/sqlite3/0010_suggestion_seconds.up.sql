@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS suggestion_seconds (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    suggestionID INTEGER NOT NULL,
+    weekID INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    dateAdded DATETIME NOT NULL DEFAULT current_timestamp,
+    CONSTRAINT fk_suggestion_seconds_suggestionID FOREIGN KEY (suggestionID) REFERENCES suggestions(id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS ix_suggestion_seconds_suggestionID_name ON suggestion_seconds(suggestionID, name);
+CREATE INDEX IF NOT EXISTS ix_suggestion_seconds_weekID ON suggestion_seconds(weekID);
@@ -0,0 +1,7 @@
+ALTER TABLE movies ADD COLUMN titleKey VARCHAR(255) NOT NULL DEFAULT '';
+-- Best effort for movies already imported, importing the dataset again fills
+-- in the keys exactly, without accents or other punctuation
+UPDATE movies SET titleKey = replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(lower(title), ' ', ''), '-', ''), ':', ''), '''', ''), '.', ''), ',', ''), '!', ''), '?', ''), '&', ''), '(', ''), ')', ''), '"', ''), '/', '');
+CREATE INDEX IF NOT EXISTS ix_movies_titleKey ON movies(titleKey);
+DROP INDEX IF EXISTS ix_movies_titleHash;
+ALTER TABLE movies DROP COLUMN titleHash;
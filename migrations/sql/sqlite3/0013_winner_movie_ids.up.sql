@@ -0,0 +1,2 @@
+ALTER TABLE winners ADD COLUMN movieID VARCHAR(32);
+UPDATE winners SET movieID = (SELECT s.movieID FROM suggestions s WHERE s.id = winners.suggestionID);
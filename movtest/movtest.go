@@ -0,0 +1,124 @@
+// Package movtest drives the mov command line end to end over in-memory
+// stores, with a clock the test controls.
+package movtest
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/bot"
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/memory"
+	"github.com/fredlawl/200-colony-movie-night-bot/runner"
+)
+
+// Clock is a clock tests move by hand.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+func (clock *Clock) Now() time.Time {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	return clock.now
+}
+
+// Set moves the clock to now.
+func (clock *Clock) Set(now time.Time) {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	clock.now = now
+}
+
+// Advance moves the clock forward by d.
+func (clock *Clock) Advance(d time.Duration) {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+
+	clock.now = clock.now.Add(d)
+}
+
+// Harness runs mov commands against a fresh in-memory database. Output of
+// every command is captured, in order, in Output.
+type Harness struct {
+	T        *testing.T
+	Clock    *Clock
+	Config   general.AppConfig
+	Database *memory.Database
+	Stores   runner.Stores
+	Output   strings.Builder
+}
+
+// New creates a harness at 12:00 on Monday the 5th of April 2021, during the
+// suggesting period of the default schedule.
+func New(t *testing.T) *Harness {
+	cfg := general.DefaultConfiguration()
+
+	loc, err := time.LoadLocation(cfg.Localization)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock := NewClock(time.Date(2021, 4, 5, 12, 0, 0, 0, loc))
+	database := memory.NewDatabase(clock)
+
+	return &Harness{
+		T:        t,
+		Clock:    clock,
+		Config:   cfg,
+		Database: database,
+		Stores: runner.Stores{
+			Suggestions: database.Suggestions(),
+			Votes:       database.Votes(),
+			Users:       database.Users(),
+			Overrides:   database.Overrides(),
+			Periods:     database.Periods(),
+			Movies:      database.Movies(),
+			History:     database.History(),
+			Exceptions:  database.Exceptions(),
+			Seconds:     database.Seconds(),
+		},
+	}
+}
+
+// Run runs the command on behalf of user, reading it the way the bot reads
+// messages, e.g. Run("liam", `suggestions add "Shrek 2"`).
+func (h *Harness) Run(user string, command string) (string, error) {
+	args, err := bot.Tokenize(command)
+	if err != nil {
+		h.T.Fatalf("unable to read %q: %v", command, err)
+	}
+
+	app := runner.NewStoreApp(h.Config, h.Stores, h.Clock, &h.Output)
+
+	return app.Execute(context.Background(), user, args)
+}
+
+// MustRun runs the command and fails the test when it returns an error.
+func (h *Harness) MustRun(user string, command string) string {
+	output, err := h.Run(user, command)
+	if err != nil {
+		h.T.Fatalf("%s: %v\n%s", command, err, output)
+	}
+
+	return output
+}
+
+// Weekday moves the clock to the given day of the current week, Monday to
+// Sunday, keeping the time of day.
+func (h *Harness) Weekday(day time.Weekday) {
+	now := h.Clock.Now()
+	sinceMonday := func(day time.Weekday) int { return (int(day) + 6) % 7 }
+
+	h.Clock.Set(now.AddDate(0, 0, sinceMonday(day)-sinceMonday(now.Weekday())))
+}
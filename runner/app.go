@@ -0,0 +1,254 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/admin"
+	"github.com/fredlawl/200-colony-movie-night-bot/auth"
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/history"
+	"github.com/fredlawl/200-colony-movie-night-bot/metadata"
+	"github.com/fredlawl/200-colony-movie-night-bot/migrations"
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+	"github.com/fredlawl/200-colony-movie-night-bot/suggestion"
+	"github.com/fredlawl/200-colony-movie-night-bot/vote"
+	"github.com/urfave/cli/v2"
+)
+
+// App is the mov application core. It is built once and then runs any number
+// of commands, reporting failures as errors instead of exiting, so the
+// command line, the bot and tests can share it.
+type App struct {
+	AppID string
+	// Sources records where the configuration came from for "config show"
+	Sources   general.ConfigSources
+	config    general.AppConfig
+	dbSession *storage.DB
+	stores    *Stores
+	clock     general.Clock
+	writer    io.Writer
+}
+
+// NewApp creates the application core. The clock decides the current week and
+// period for each command. Output is returned from every command and, when
+// writer isn't nil, copied to writer as it is written.
+//
+// When dbSession is nil each command opens the database named by the
+// configuration, after global flags are applied, and closes it afterwards.
+func NewApp(cfg general.AppConfig, dbSession *storage.DB, clock general.Clock, writer io.Writer) *App {
+	return &App{
+		Sources:   general.ConfigSources{},
+		config:    cfg,
+		dbSession: dbSession,
+		clock:     clock,
+		writer:    writer,
+	}
+}
+
+// NewStoreApp creates the application core over the given stores instead of
+// a database, such as the in-memory stores tests use.
+func NewStoreApp(cfg general.AppConfig, stores Stores, clock general.Clock, writer io.Writer) *App {
+	app := NewApp(cfg, nil, clock, writer)
+	app.stores = &stores
+
+	return app
+}
+
+// Execute runs a mov command on behalf of user. The args start with the
+// command name, such as "suggestions list".
+func (app *App) Execute(ctx context.Context, user string, args []string) (string, error) {
+	return app.Run(ctx, append([]string{"mov", "--user", user}, args...))
+}
+
+// Run runs a complete mov command line, starting with the program name and
+// including the global flags.
+func (app *App) Run(ctx context.Context, args []string) (output string, err error) {
+	var outputBuffer bytes.Buffer
+	var writer io.Writer = &outputBuffer
+	if app.writer != nil {
+		writer = io.MultiWriter(&outputBuffer, app.writer)
+	}
+
+	// A broken command must not take a long running process down with it
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			output = outputBuffer.String()
+			err = fmt.Errorf("panic running %v: %v", args, recovered)
+		}
+	}()
+
+	cliApp := newCliApp()
+	cliApp.Writer = writer
+	cliApp.ErrWriter = writer
+	cliApp.Before = app.prepare
+	cliApp.After = app.release
+
+	err = cliApp.RunContext(ctx, args)
+
+	return outputBuffer.String(), err
+}
+
+// prepare layers the global flags over the configuration and establishes the
+// settings and database the commands use.
+func (app *App) prepare(c *cli.Context) error {
+	cfg := app.config
+	sources := general.ConfigSources{}
+	for name, source := range app.Sources {
+		sources[name] = source
+	}
+
+	for _, field := range general.ConfigFields() {
+		if !c.IsSet(field.Name) {
+			continue
+		}
+
+		if err := cfg.Set(field.Name, c.String(field.Name)); err != nil {
+			return err
+		}
+		sources[field.Name] = "flag --" + field.Name
+	}
+
+	stores, dbErr := app.openStores(c, cfg)
+	if dbErr != nil {
+		return dbErr
+	}
+	stores.setMetadata(c.App.Metadata)
+
+	// The db commands run before the overrides table may exist
+	overrides := stores.Overrides
+	if managesSchema(c) {
+		overrides = nil
+	}
+
+	settings, settingsErr := general.LoadAppSettings(c.Context, cfg, app.clock.Now(), overrides)
+	if settingsErr != nil {
+		return settingsErr
+	}
+
+	settings.AppID = app.AppID
+	settings.Sources = sources
+
+	c.App.Metadata["settings"] = settings
+
+	return nil
+}
+
+// openStores returns the stores the App was given, otherwise the SQL stores
+// of its database, opening the configured one when it has none.
+func (app *App) openStores(c *cli.Context, cfg general.AppConfig) (Stores, error) {
+	if app.stores != nil {
+		return *app.stores, nil
+	}
+
+	dbSession := app.dbSession
+	if dbSession == nil {
+		var dbErr error
+		if dbSession, dbErr = OpenDatabase(cfg); dbErr != nil {
+			return Stores{}, dbErr
+		}
+
+		// Metadata is set first so the database is closed if anything fails
+		c.App.Metadata["dbSession"] = dbSession
+
+		// The db commands manage migrations themselves
+		if cfg.AutoMigrate && !managesSchema(c) {
+			if err := migrate(dbSession); err != nil {
+				return Stores{}, err
+			}
+		}
+	}
+
+	c.App.Metadata["dbSession"] = dbSession
+
+	return SQLStores(dbSession), nil
+}
+
+// managesSchema reports whether the command is one of the db commands, which
+// run against a database whatever its schema.
+func managesSchema(c *cli.Context) bool {
+	return c.Args().First() == "db"
+}
+
+func (app *App) release(c *cli.Context) error {
+	dbSession, opened := c.App.Metadata["dbSession"].(*storage.DB)
+	if !opened || dbSession == app.dbSession {
+		return nil
+	}
+
+	return dbSession.Close()
+}
+
+// migrate applies pending migrations, logging each one applied.
+func migrate(dbSession *storage.DB) error {
+	migrator, err := migrations.NewMigrator(dbSession)
+	if err != nil {
+		return err
+	}
+
+	migrated, err := migrator.Migrate()
+	for _, migration := range migrated {
+		log.Printf("[info] applied migration %s", migration)
+	}
+
+	return err
+}
+
+// OpenDatabase opens the SQLite or PostgreSQL database named by the
+// configuration.
+func OpenDatabase(cfg general.AppConfig) (*storage.DB, error) {
+	return storage.Open(cfg.DbDriver, cfg.DbFilePath)
+}
+
+func newCliApp() *cli.App {
+	flags := []cli.Flag{
+		// Because this application depends on multiple users, we need
+		// to supply the user calling these commands. Further, the option
+		// is hidden so that it does not appear in documenation so a user
+		// doesn't have to supply it, but the bot will behind the scenes.
+		&cli.StringFlag{
+			Name:     "user",
+			Aliases:  []string{"u"},
+			Usage:    "user interfacing with the application",
+			Hidden:   true,
+			Required: true,
+		},
+	}
+
+	// Every configuration value can be overridden for a single run
+	for _, field := range general.ConfigFields() {
+		flags = append(flags, &cli.StringFlag{
+			Name:  field.Name,
+			Usage: field.Usage,
+		})
+	}
+
+	return &cli.App{
+		Metadata: map[string]interface{}{},
+		Name:     "mov",
+		HelpName: "mov",
+		Usage:    "an application to manage movie night movie suggestions and votes.",
+		Flags:    flags,
+		Commands: Commands(),
+		// Errors are returned to the caller, never turned into an exit
+		ExitErrHandler: func(c *cli.Context, err error) {},
+	}
+}
+
+// Commands are the mov subcommands, shared by the command line and the
+// slash commands the bot generates from them.
+func Commands() []*cli.Command {
+	return []*cli.Command{
+		suggestion.Command(),
+		vote.Command(),
+		admin.Command(),
+		auth.Command(),
+		history.Command(),
+		metadata.Command(),
+		migrations.Command(),
+		configCommand(),
+	}
+}
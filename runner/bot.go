@@ -0,0 +1,91 @@
+package runner
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fredlawl/200-colony-movie-night-bot/bot"
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/google/uuid"
+)
+
+// RunBot connects to Discord and runs the mov command line for every message
+// addressed to the bot until the process is interrupted.
+func RunBot() {
+	appID := uuid.New().String()
+
+	errorLogFile := openErrorLog(appID)
+	defer errorLogFile.Close()
+
+	cfg, sources, cfgErr := general.LoadConfig(os.Environ())
+	if cfgErr != nil {
+		log.Fatalf("[error] %s error loading configuration %+v", appID, cfgErr)
+		return
+	}
+
+	if cfg.DiscordToken == "" {
+		log.Fatalf("[error] %s MOV_DISCORD_TOKEN is not set", appID)
+		return
+	}
+
+	dbSession, dbSessionErr := OpenDatabase(cfg)
+	if dbSessionErr != nil {
+		log.Fatalf("[error] %s error opening database %+v", appID, dbSessionErr)
+		return
+	}
+	defer dbSession.Close()
+
+	// Discord delivers messages concurrently, let SQLite see one at a time
+	dbSession.SetMaxOpenConns(1)
+
+	if cfg.AutoMigrate {
+		if err := migrate(dbSession); err != nil {
+			log.Fatalf("[error] %s error migrating database %+v", appID, err)
+			return
+		}
+	}
+
+	session, sessionErr := discordgo.New("Bot " + cfg.DiscordToken)
+	if sessionErr != nil {
+		log.Fatalf("[error] %s error creating discord session %+v", appID, sessionErr)
+		return
+	}
+
+	session.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentMessageContent
+
+	app := NewApp(cfg, dbSession, general.SystemClock{}, nil)
+	app.AppID = appID
+	app.Sources = sources
+
+	movieBot := bot.NewBot(session, app.Execute, Commands(), bot.Options{
+		ChannelID: cfg.DiscordChannelID,
+		Prefix:    cfg.CommandPrefix,
+		GuildID:   cfg.DiscordGuildID,
+	})
+	if openErr := movieBot.Open(); openErr != nil {
+		log.Fatalf("[error] %s error connecting to discord %+v", appID, openErr)
+		return
+	}
+	defer movieBot.Close()
+
+	stores := SQLStores(dbSession)
+	lifecycle := general.NewLifecycle(cfg, stores.Periods, stores.Overrides, general.SystemClock{})
+	lifecycle.Subscribe(carrier(stores, cfg))
+	lifecycle.Subscribe(recorder(stores, cfg))
+	lifecycle.Subscribe(announcer(app, movieBot, cfg.CommandPrefix))
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	go lifecycle.Watch(ctx, lifecycleInterval, func(err error) {
+		log.Printf("[error] %s period transition %+v", appID, err)
+	})
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	<-interrupt
+}
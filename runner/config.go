@@ -0,0 +1,61 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/auth"
+	"github.com/fredlawl/200-colony-movie-night-bot/bot"
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/urfave/cli/v2"
+)
+
+func configCommand() *cli.Command {
+	description := `Show the configuration in effect and where each value came from:
+    mov config show
+
+	Values are layered from lowest to highest precedence: defaults, the config file (MOV_CONFIG or mov.yaml), MOV_* environment variables, then global flags. Only admins may show the configuration, and secrets such as the database connection string are masked.
+`
+
+	return &cli.Command{
+		Name:        "config",
+		Usage:       "shows the app configuration",
+		Description: description,
+		Subcommands: []*cli.Command{
+			{
+				Name:     "show",
+				Aliases:  []string{"s"},
+				Usage:    "Shows the effective configuration",
+				Category: bot.PrivateCategory,
+				Action:   showConfigAction,
+			},
+		},
+	}
+}
+
+func showConfigAction(c *cli.Context) error {
+	if allowed, err := auth.Require(c, auth.ViewConfig); !allowed {
+		return err
+	}
+
+	settings := c.App.Metadata["settings"].(*general.AppSettings)
+
+	var outputBuffer strings.Builder
+
+	outputBuffer.WriteString(fmt.Sprintf("%-18s%-33.32s%s\n", "Name", "Value", "Source"))
+
+	for _, field := range general.ConfigFields() {
+		value := settings.Config.Get(field.Name)
+		if field.Secret && value != "" {
+			value = "********"
+		}
+
+		outputBuffer.WriteString(fmt.Sprintf("%-18s%-33.32s%s\n",
+			field.Name,
+			value,
+			settings.Sources.Source(field.Name)))
+	}
+
+	_, writeErr := c.App.Writer.Write([]byte(outputBuffer.String()))
+	return writeErr
+}
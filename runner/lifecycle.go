@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/bot"
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/vote"
+)
+
+// How often the bot looks for period transitions.
+const lifecycleInterval = time.Minute
+
+// Announcements run their commands as this user.
+const announcerUser = "movie-night"
+
+// announcer posts period transitions to the bot's channel, with the ballot
+// when voting opens and the results once movie night starts.
+func announcer(app *App, movieBot *bot.Bot, prefix string) general.Subscriber {
+	return general.SubscriberFunc(func(ctx context.Context, transition general.Transition) error {
+		var message string
+		var args []string
+
+		switch {
+		case transition.Opened(general.Suggesting):
+			message = fmt.Sprintf("Suggestions are open for week %s! Add one with \"%s suggestions add\".", transition.WeekID, prefix)
+			if app.config.CarryOver != 0 {
+				// Show what was carried over from last week
+				args = []string{"suggestions", "list"}
+			}
+		case transition.Opened(general.Voting):
+			message = fmt.Sprintf("Voting is open! Rank the suggestions with \"%s votes cast\".", prefix)
+			args = []string{"suggestions", "list", "--pitches"}
+		case transition.Opened(general.MovieNight):
+			message = "Voting has closed, here are the results."
+			args = []string{"votes", "results"}
+		default:
+			return nil
+		}
+
+		var output string
+		if args != nil {
+			var err error
+			if output, err = app.Execute(ctx, announcerUser, args); err != nil {
+				return err
+			}
+		}
+
+		return movieBot.Announce(message, output)
+	})
+}
+
+// carrier copies last week's runners-up into the week when its suggestions
+// open. It has to be subscribed before the announcer to be announced.
+func carrier(stores Stores, cfg general.AppConfig) general.Subscriber {
+	return general.SubscriberFunc(func(ctx context.Context, transition general.Transition) error {
+		if !transition.Opened(general.Suggesting) {
+			return nil
+		}
+
+		settings, err := general.WeekSettings(ctx, cfg, transition.WeekID, stores.Overrides)
+		if err != nil {
+			return err
+		}
+
+		carried, err := vote.CarryOver(ctx, stores.Suggestions, stores.Votes, stores.Seconds, stores.History, settings, transition.WeekID.Previous(), cfg.CarryOver)
+		for _, s := range carried {
+			log.Printf("[info] carried %s by %s over to week %s", s.Movie, s.Author, transition.WeekID)
+		}
+
+		return err
+	})
+}
+
+// recorder records the week's winner once movie night starts. A winner
+// already recorded, by an admin or before a restart, is kept.
+func recorder(stores Stores, cfg general.AppConfig) general.Subscriber {
+	return general.SubscriberFunc(func(ctx context.Context, transition general.Transition) error {
+		if !transition.Opened(general.MovieNight) {
+			return nil
+		}
+
+		settings, err := general.WeekSettings(ctx, cfg, transition.WeekID, stores.Overrides)
+		if err != nil {
+			return err
+		}
+
+		winner, err := vote.RecordWinner(ctx, stores.Votes, stores.Seconds, settings)
+		if errors.Is(err, vote.ErrWinnerRecorded) {
+			return nil
+		}
+
+		if winner != nil {
+			log.Printf("[info] recorded %s by %s as the winner of week %s", winner.Movie, winner.Author, transition.WeekID)
+		}
+
+		return err
+	})
+}
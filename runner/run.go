@@ -1,7 +1,7 @@
 package runner
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -9,14 +9,43 @@ import (
 	"time"
 
 	"github.com/fredlawl/200-colony-movie-night-bot/general"
-	"github.com/fredlawl/200-colony-movie-night-bot/suggestion"
-	"github.com/fredlawl/200-colony-movie-night-bot/vote"
 	"github.com/google/uuid"
-	"github.com/urfave/cli/v2"
 )
 
 func Run(args []string) {
 	appID := uuid.New().String()
+
+	errorLogFile := openErrorLog(appID)
+	defer errorLogFile.Close()
+
+	cfg, sources, cfgErr := general.LoadConfig(os.Environ())
+	if cfgErr != nil {
+		log.Fatalf("[error] %s error loading configuration %+v", appID, cfgErr)
+		return
+	}
+
+	// The database is opened per run so the --db flag is respected
+	app := NewApp(cfg, nil, general.SystemClock{}, os.Stdout)
+	app.AppID = appID
+	app.Sources = sources
+
+	output, cliErr := app.Run(context.Background(), args)
+	if cliErr != nil {
+		log.Printf("[error] %s",
+			strings.Join(os.Args, " "))
+		log.Printf("[error] %+v", cliErr)
+
+		// Commands explain their failures, the rest are only in the log
+		if strings.TrimSpace(output) == "" {
+			fmt.Fprintln(os.Stderr, "Something went wrong running that command, see the error log.")
+		}
+
+		errorLogFile.Close()
+		os.Exit(1)
+	}
+}
+
+func openErrorLog(appID string) *os.File {
 	now := time.Now()
 	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).Format(time.RFC3339)
 	errorLogName := fmt.Sprintf("logs/%s.error.log", today)
@@ -27,74 +56,11 @@ func Run(args []string) {
 
 	if errorLogFileErr != nil {
 		log.Fatalf("[error] %s error creating logfile %+v", appID, errorLogFileErr)
-		return
+		return nil
 	}
-	defer errorLogFile.Close()
 
 	log.SetOutput(errorLogFile)
 	log.SetPrefix(appID + " - ")
 
-	// Load app settings
-	cfg := general.DefaultConfiguration()
-	settings, settingsErr := general.CreateAppSettings(cfg)
-	if settingsErr != nil {
-		log.Fatalf("[error] %s error establishing settings %+v", appID, settingsErr)
-		return
-	}
-
-	settings.AppID = appID
-
-	dbSession, dbSessionErr := sql.Open("sqlite3", settings.Config.DbFilePath)
-	if dbSessionErr != nil {
-		log.Fatalf("[error] %s error establishing settings %+v", appID, dbSessionErr)
-		return
-	}
-	defer dbSession.Close()
-
-	// SQLITE3 does not have foreign_keys turned on by default
-	dbSession.Exec("PRAGMA foreign_keys = ON")
-
-	// Load CLI
-	app := &cli.App{
-		Metadata: map[string]interface{}{
-			"settings":  settings,
-			"dbSession": dbSession,
-		},
-		Name:     "mov",
-		HelpName: "mov",
-		Usage:    "an application to manage movie night movie suggestions and votes.",
-		Flags: []cli.Flag{
-			// Because this application depends on multiple users, we need
-			// to supply the user calling these commands. Further, the option
-			// is hidden so that it does not appear in documenation so a user
-			// doesn't have to supply it, but the bot will behind the scenes.
-			&cli.StringFlag{
-				Name:     "user",
-				Aliases:  []string{"u"},
-				Usage:    "user interfacing with the application",
-				Hidden:   true,
-				Required: true,
-			},
-			// To help with testing, allow app to bypass Period restrictions
-			&cli.BoolFlag{
-				Name:     "bypass",
-				Aliases:  []string{"bp"},
-				Usage:    "disable app state check",
-				Hidden:   true,
-				Required: false,
-				Value:    false,
-			},
-		},
-		Commands: []*cli.Command{
-			suggestion.Command(),
-			vote.Command(),
-		},
-	}
-
-	cliErr := app.Run(args)
-	if cliErr != nil {
-		log.Printf("[error] %s",
-			strings.Join(os.Args, " "))
-		log.Fatalf("[error] %+v", cliErr)
-	}
+	return errorLogFile
 }
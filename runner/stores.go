@@ -0,0 +1,52 @@
+package runner
+
+import (
+	"github.com/fredlawl/200-colony-movie-night-bot/auth"
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/history"
+	"github.com/fredlawl/200-colony-movie-night-bot/metadata"
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+	"github.com/fredlawl/200-colony-movie-night-bot/suggestion"
+	"github.com/fredlawl/200-colony-movie-night-bot/vote"
+)
+
+// Stores are the repositories the commands read and write through. Each is
+// put in the cli metadata under the name commands look it up by.
+type Stores struct {
+	Suggestions suggestion.Store
+	Votes       vote.Store
+	Users       auth.Store
+	Overrides   general.OverrideStore
+	Periods     general.PeriodStore
+	Movies      metadata.Store
+	History     history.Store
+	Exceptions  suggestion.ExceptionStore
+	Seconds     suggestion.SecondStore
+}
+
+// SQLStores creates the repositories backed by a SQLite or PostgreSQL
+// database.
+func SQLStores(dbSession *storage.DB) Stores {
+	return Stores{
+		Suggestions: suggestion.NewRepository(dbSession),
+		Votes:       vote.NewRepository(dbSession),
+		Users:       auth.NewRepository(dbSession),
+		Overrides:   general.NewOverrideRepository(dbSession),
+		Periods:     general.NewPeriodRepository(dbSession),
+		Movies:      metadata.NewRepository(dbSession),
+		History:     history.NewRepository(dbSession),
+		Exceptions:  suggestion.NewExceptionRepository(dbSession),
+		Seconds:     suggestion.NewSecondRepository(dbSession),
+	}
+}
+
+func (stores Stores) setMetadata(metadata map[string]interface{}) {
+	metadata["suggestions"] = stores.Suggestions
+	metadata["votes"] = stores.Votes
+	metadata["users"] = stores.Users
+	metadata["overrides"] = stores.Overrides
+	metadata["movies"] = stores.Movies
+	metadata["history"] = stores.History
+	metadata["exceptions"] = stores.Exceptions
+	metadata["seconds"] = stores.Seconds
+}
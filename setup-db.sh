@@ -1,7 +1,5 @@
 #!/usr/bin/env sh
 
-DB_FILE="sqlite-cli.db"
-DB_MIGRATION="migration.sql"
-
-touch "$DB_FILE"
-sqlite3 "$DB_FILE" < "$DB_MIGRATION"
\ No newline at end of file
+# Migrations are embedded in mov and applied whenever it starts, this applies
+# them without running any other command.
+MOV_DB="sqlite-cli.db" MOV_ADMINS="setup" go run ./cmd/cli --user setup db migrate
@@ -0,0 +1,196 @@
+// Package storage opens the SQL database behind the repositories and hides
+// the differences between the SQLite and PostgreSQL drivers from them.
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	SQLite   = "sqlite3"
+	Postgres = "postgres"
+)
+
+var (
+	// ErrUniqueViolation is returned when a write conflicts with a unique
+	// index. Repositories translate it to a domain error.
+	ErrUniqueViolation = errors.New("unique constraint violated")
+	// ErrForeignKeyViolation is returned when a write references a row
+	// that doesn't exist.
+	ErrForeignKeyViolation = errors.New("foreign key constraint violated")
+)
+
+// DB is a database connection pool along with the driver it was opened with.
+// Repositories write queries with ? placeholders and run them through
+// Rebind.
+type DB struct {
+	*sql.DB
+	Driver string
+}
+
+// Open connects to the database. For SQLite the source is a file path, for
+// PostgreSQL it is a connection string such as
+// "postgres://mov@localhost/mov?sslmode=disable".
+func Open(driver string, source string) (*DB, error) {
+	switch driver {
+	case SQLite:
+		// SQLITE3 does not have foreign_keys turned on by default, and the
+		// pragma only applies to a single connection of the pool
+		source = fmt.Sprintf("file:%s?_foreign_keys=on", source)
+	case Postgres:
+	default:
+		return nil, fmt.Errorf("unknown database driver \"%s\", try %s or %s", driver, SQLite, Postgres)
+	}
+
+	session, err := sql.Open(driver, source)
+	if err != nil {
+		return nil, err
+	}
+
+	return Wrap(session, driver), nil
+}
+
+// Wrap adopts an already open connection pool.
+func Wrap(session *sql.DB, driver string) *DB {
+	return &DB{
+		DB:     session,
+		Driver: driver,
+	}
+}
+
+// Rebind rewrites ? placeholders to the driver's placeholder syntax. Queries
+// must not use ? anywhere else, such as in string literals.
+func (db *DB) Rebind(query string) string {
+	if db.Driver != Postgres {
+		return query
+	}
+
+	var rebound strings.Builder
+	n := 0
+	for _, r := range query {
+		if r != '?' {
+			rebound.WriteRune(r)
+			continue
+		}
+
+		n++
+		rebound.WriteString("$" + strconv.Itoa(n))
+	}
+
+	return rebound.String()
+}
+
+// Exec runs a query written with ? placeholders.
+func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.ExecContext(context.Background(), query, args...)
+}
+
+// ExecContext runs a query written with ? placeholders.
+func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	result, err := db.DB.ExecContext(ctx, db.Rebind(query), args...)
+	return result, Translate(err)
+}
+
+// Query runs a query written with ? placeholders.
+func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.QueryContext(context.Background(), query, args...)
+}
+
+// QueryContext runs a query written with ? placeholders.
+func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := db.DB.QueryContext(ctx, db.Rebind(query), args...)
+	return rows, Translate(err)
+}
+
+// QueryRow runs a query written with ? placeholders.
+func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.QueryRowContext(context.Background(), query, args...)
+}
+
+// QueryRowContext runs a query written with ? placeholders.
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return db.DB.QueryRowContext(ctx, db.Rebind(query), args...)
+}
+
+// Tx is a transaction that rebinds its queries like DB.
+type Tx struct {
+	*sql.Tx
+	db *DB
+}
+
+func (db *DB) Begin() (*Tx, error) {
+	return db.BeginTx(context.Background(), nil)
+}
+
+// BeginTx starts a transaction that is rolled back if ctx is done before it
+// is committed.
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := db.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tx{Tx: tx, db: db}, nil
+}
+
+func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.ExecContext(context.Background(), query, args...)
+}
+
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	result, err := tx.Tx.ExecContext(ctx, tx.db.Rebind(query), args...)
+	return result, Translate(err)
+}
+
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := tx.Tx.QueryContext(ctx, tx.db.Rebind(query), args...)
+	return rows, Translate(err)
+}
+
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return tx.Tx.QueryRowContext(ctx, tx.db.Rebind(query), args...)
+}
+
+// PrepareContext prepares a statement written with ? placeholders to run
+// many times on the transaction.
+func (tx *Tx) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return tx.Tx.PrepareContext(ctx, tx.db.Rebind(query))
+}
+
+// Translate turns the drivers' constraint errors into ErrUniqueViolation and
+// ErrForeignKeyViolation, wrapping the original error.
+func Translate(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.ExtendedCode {
+		case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+			return fmt.Errorf("%w: %v", ErrUniqueViolation, err)
+		case sqlite3.ErrConstraintForeignKey:
+			return fmt.Errorf("%w: %v", ErrForeignKeyViolation, err)
+		}
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Name() {
+		case "unique_violation":
+			return fmt.Errorf("%w: %v", ErrUniqueViolation, err)
+		case "foreign_key_violation":
+			return fmt.Errorf("%w: %v", ErrForeignKeyViolation, err)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestGivenPostgresRebindNumbersThePlaceholders(t *testing.T) {
+	db := &DB{Driver: Postgres}
+
+	if db.Rebind("SELECT * FROM votes WHERE weekID = ? AND author = ?") != "SELECT * FROM votes WHERE weekID = $1 AND author = $2" {
+		t.Fail()
+	}
+}
+
+func TestGivenSQLiteRebindKeepsTheQuery(t *testing.T) {
+	db := &DB{Driver: SQLite}
+
+	if db.Rebind("SELECT ?") != "SELECT ?" {
+		t.Fail()
+	}
+}
+
+func TestGivenASQLiteUniqueViolationItIsTranslated(t *testing.T) {
+	db, err := Open(SQLite, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(`CREATE TABLE movies (name VARCHAR(255) PRIMARY KEY)`); err != nil {
+		t.Fatal(err)
+	}
+
+	db.Exec(`INSERT INTO movies (name) VALUES (?)`, "Shrek")
+	_, err = db.Exec(`INSERT INTO movies (name) VALUES (?)`, "Shrek")
+
+	if !errors.Is(err, ErrUniqueViolation) {
+		t.Fail()
+	}
+}
+
+func TestGivenAPostgresForeignKeyViolationItIsTranslated(t *testing.T) {
+	err := Translate(&pq.Error{Code: "23503"})
+
+	if !errors.Is(err, ErrForeignKeyViolation) {
+		t.Fail()
+	}
+}
+
+func TestGivenAnUnknownDriverOpenFails(t *testing.T) {
+	if _, err := Open("mysql", "mov"); err == nil {
+		t.Fail()
+	}
+}
@@ -1,27 +1,58 @@
 package suggestion
 
 import (
-	"database/sql"
+	"errors"
 	"fmt"
+	"net/url"
 	"strconv"
 	"strings"
 
+	"github.com/fredlawl/200-colony-movie-night-bot/auth"
 	"github.com/fredlawl/200-colony-movie-night-bot/general"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/fredlawl/200-colony-movie-night-bot/history"
+	"github.com/fredlawl/200-colony-movie-night-bot/metadata"
 	"github.com/urfave/cli/v2"
 )
 
 func Command() *cli.Command {
-	description := `List this weeks suggestions:
-    mov suggestions list
+	description := `List this weeks suggestions, with an excerpt of each pitch:
+    mov suggestions list [--pitches]
 
-Add suggestion:
-    mov suggestions add "[movie name]"
+Show a suggestion with its whole pitch and link:
+    mov suggestions show [id]
+
+Add suggestion, with why everyone should watch it and a link to a trailer or review:
+    mov suggestions add [--force] [--movie id] [--as-written] [--pitch "why we should watch"] [--link url] "[movie name]"
+
+	Movies found in the catalog are named with their year, like "Dune (2021)". When several movies share the title, pick one by the id shown or add the year to the title. A catalog movie titled differently, like "Spider-Man" for "spiderman", is only used once picked by its id, or the title is kept with --as-written. Titles not in the catalog are added as written.
+
+	Movies that look like one already suggested this week, such as "Shrek II" after "Shrek 2" or "Shreck" after "Shrek", are only added with --force.
+
+Movies watched within the rewatch cooldown, 12 months by default, can't be suggested again. See them with:
+    mov history
+
+Carry your suggestions from last week, or from another week, into this week:
+    mov suggestions carry [--week YYYYWW] [id]...
+
+	Without ids all of your suggestions of that week are carried. The best runners-up of last week are carried over when the week starts.
+
+Fix the title of a suggestion, keeping its number and votes:
+    mov suggestions edit [--movie id] [--as-written] [id] "[movie name]"
+
+	Titles can be edited until voting closes, though once voting starts only their spelling may be fixed. The previous titles are shown with the suggestion.
 
 Remove suggestion:
 	mov suggestions remove [id]
 
-	Only users may remove their own suggestions.
+	Only users may edit or remove their own suggestions, unless they are a moderator.
+
+Back someone else's suggestion while suggestions are open:
+    mov suggestions second [id]
+
+	When a nomination threshold is configured only suggestions seconded that many times make the ballot.
+
+Each user may add 3 suggestions a week, and the ballot holds 20, unless configured otherwise. Moderators may let a user add one more:
+    mov suggestions grant [user]
 `
 
 	return &cli.Command{
@@ -34,19 +65,104 @@ Remove suggestion:
 				Name:    "list",
 				Aliases: []string{"l"},
 				Usage:   "Lists suggested movies",
-				Action:  listMoviesAction,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "pitches",
+						Aliases: []string{"p"},
+						Usage:   "include an excerpt of each pitch",
+					},
+				},
+				Action: listMoviesAction,
 			},
 			{
-				Name:    "add",
-				Aliases: []string{"a"},
-				Usage:   "Suggest a movie",
-				Action:  suggestMovieAction,
+				Name:      "second",
+				Usage:     "Backs a suggestion so it makes the ballot",
+				ArgsUsage: "<id>",
+				Action:    secondMovieAction,
 			},
 			{
-				Name:    "remove",
-				Aliases: []string{"rm"},
-				Usage:   "Remove suggestion",
-				Action:  removeMovieAction,
+				Name:      "show",
+				Usage:     "Shows a suggestion with its pitch",
+				ArgsUsage: "<id>",
+				Action:    showMovieAction,
+			},
+			{
+				Name:      "add",
+				Aliases:   []string{"a"},
+				Usage:     "Suggest a movie",
+				ArgsUsage: "<movie>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "movie",
+						Aliases: []string{"m"},
+						Usage:   "catalog id of the movie, when several share the title",
+					},
+					&cli.BoolFlag{
+						Name:  "as-written",
+						Usage: "keep the title as written instead of a catalog movie titled differently",
+					},
+					&cli.BoolFlag{
+						Name:    "force",
+						Aliases: []string{"f"},
+						Usage:   "add the movie even if it looks like one already suggested",
+					},
+					&cli.StringFlag{
+						Name:    "pitch",
+						Aliases: []string{"p"},
+						Usage:   "why everyone should watch the movie",
+					},
+					&cli.StringFlag{
+						Name:    "link",
+						Aliases: []string{"l"},
+						Usage:   "link to a trailer or review",
+					},
+				},
+				Action: suggestMovieAction,
+			},
+			{
+				Name:      "carry",
+				Aliases:   []string{"c"},
+				Usage:     "Carries your suggestions from a past week into this week",
+				ArgsUsage: "[ids...]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "week",
+						Aliases: []string{"w"},
+						Usage:   "week to carry from formatted as YYYYWW, defaults to last week",
+					},
+				},
+				Action: carryMoviesAction,
+			},
+			{
+				Name:      "edit",
+				Aliases:   []string{"e"},
+				Usage:     "Changes the title of a suggestion",
+				ArgsUsage: "<id> <movie>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "movie",
+						Aliases: []string{"m"},
+						Usage:   "catalog id of the movie, when several share the title",
+					},
+					&cli.BoolFlag{
+						Name:  "as-written",
+						Usage: "keep the title as written instead of a catalog movie titled differently",
+					},
+				},
+				Action: editMovieAction,
+			},
+			{
+				Name:      "remove",
+				Aliases:   []string{"rm"},
+				Usage:     "Remove suggestion",
+				ArgsUsage: "<id>",
+				Action:    removeMovieAction,
+			},
+			{
+				Name:      "grant",
+				Usage:     "Lets a user add one suggestion past this week's limits",
+				ArgsUsage: "<user>",
+				Action:    grantExceptionAction,
 			},
 		},
 	}
@@ -54,62 +170,397 @@ Remove suggestion:
 
 func suggestMovieAction(c *cli.Context) error {
 	settings := c.App.Metadata["settings"].(*general.AppSettings)
-	dbSession := c.App.Metadata["dbSession"].(*sql.DB)
-
-	suggestionRepository := NewRepository(dbSession)
+	suggestionRepository := c.App.Metadata["suggestions"].(Store)
 
 	if c.NArg() < 1 {
 		_, writeErr := c.App.Writer.Write([]byte("Movie name not provided as argument.\n"))
 		return writeErr
 	}
 
-	if settings.CurPeriod.Name != general.Suggesting && !c.Bool("bypass") {
-		_, writeErr := c.App.Writer.Write([]byte("Sorry, unable to add the movie to suggestions. The suggestion period has already ended.\n"))
+	ignorePeriods, authErr := auth.Allowed(c, auth.IgnorePeriods)
+	if authErr != nil {
+		return authErr
+	}
+
+	if settings.CurPeriod.Name != general.Suggesting && !ignorePeriods {
+		_, writeErr := c.App.Writer.Write([]byte("Sorry, unable to add the movie to suggestions. " + settings.ClosedReason(general.Suggesting) + "\n"))
+		return writeErr
+	}
+
+	pitch := strings.TrimSpace(c.String("pitch"))
+	if len([]rune(pitch)) > maxPitchLength {
+		_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("Sorry, pitches can be at most %d characters.\n", maxPitchLength)))
+		return writeErr
+	}
+
+	link := strings.TrimSpace(c.String("link"))
+	if link != "" && !validLink(link) {
+		_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("\"%s\" is not a valid link. Links start with http:// or https://.\n", link)))
 		return writeErr
 	}
 
-	suggestion, err := NewSuggestion(settings.WeekID, c.String("user"), general.MovieFromString(c.Args().First()))
+	movie := general.MovieFromString(c.Args().First())
+	record, resolved, err := resolveMovie(c, c.Args().First())
+	if err != nil || !resolved {
+		return err
+	}
+
+	if record != nil {
+		movie = general.MovieFromString(record.String())
+	}
+
+	suggestion, err := NewSuggestion(settings.WeekID, c.String("user"), movie)
+	if errors.Is(err, ErrUntitledMovie) {
+		return writeUntitled(c, c.Args().First())
+	}
+
 	if err != nil {
-		c.App.Writer.Write([]byte(err.Error() + "\n"))
+		c.App.Writer.Write([]byte("Unable to add the movie.\n"))
 		return err
 	}
 
-	saveErr := suggestionRepository.Save(*suggestion)
-	if saveErr != nil {
+	if record != nil {
+		suggestion.MovieID = record.ID
+	}
+
+	suggestion.Pitch = pitch
+	suggestion.Link = link
+
+	watchedWarning, refused, err := checkWatched(c, settings, *suggestion)
+	if err != nil || refused {
+		return err
+	}
+
+	suggestions, err := suggestionRepository.AllSuggestions(c.Context, settings.WeekID)
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load suggestions.\n"))
+		return err
+	}
+
+	exception, refusal, err := checkLimits(c, settings, CheckLimits(settings.Config, suggestions, suggestion.Author), suggestions, suggestion.Author)
+	if err != nil {
+		return err
+	}
+
+	if refusal != "" {
+		_, writeErr := c.App.Writer.Write([]byte(refusal))
+		return writeErr
+	}
+
+	if !c.Bool("force") {
+		if similar := findSimilar(suggestions, suggestion.Movie); similar != nil {
+			_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("Did you mean #%d %s? To add \"%s\" anyway use: mov suggestions add --force \"%s\"\n",
+				similar.Order, similar.Movie.String(), suggestion.Movie.String(), suggestion.Movie.String())))
+			return writeErr
+		}
+	}
+
+	saveErr := suggestionRepository.Save(c.Context, *suggestion)
+	if errors.Is(saveErr, ErrDuplicateMovie) {
 		c.App.Writer.Write([]byte(fmt.Sprintf("Movie \"%s\" was already suggested.\n", suggestion.Movie.String())))
 		return saveErr
 	}
 
+	if saveErr != nil {
+		c.App.Writer.Write([]byte("Unable to save the suggestion.\n"))
+		return saveErr
+	}
+
+	if exception {
+		if err := useException(c, settings, suggestion.Author); err != nil {
+			return err
+		}
+	}
+
+	if watchedWarning != "" {
+		_, writeErr := c.App.Writer.Write([]byte(watchedWarning))
+		return writeErr
+	}
+
 	return nil
 }
 
+// Pitches longer than this are refused.
+const maxPitchLength = 1000
+
+// Pitches are cut to this length in listings.
+const excerptLength = 60
+
+// writeUntitled refuses a title that has no letters or digits to tell it
+// apart from other titles.
+func writeUntitled(c *cli.Context, title string) error {
+	_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("\"%s\" is not a valid title. Titles need at least one letter or digit.\n", title)))
+	return writeErr
+}
+
+func validLink(link string) bool {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// checkLimits refuses suggestions past a limit found by CheckLimits, listing
+// the user's suggestions so they can pick one to remove. A moderator's
+// exception lets the suggestion through, and is spent once it is saved.
+func checkLimits(c *cli.Context, settings *general.AppSettings, limit Limit, suggestions []Suggestion, author string) (exception bool, refusal string, err error) {
+	var own []Suggestion
+	for _, s := range suggestions {
+		if s.Author == author {
+			own = append(own, s)
+		}
+	}
+
+	var outputBuffer strings.Builder
+	switch limit {
+	case SuggestionLimit:
+		outputBuffer.WriteString(fmt.Sprintf("Sorry, you can only suggest %d movies a week.", settings.Config.SuggestionLimit))
+	case BallotLimit:
+		outputBuffer.WriteString(fmt.Sprintf("Sorry, the ballot is full with %d movies.", settings.Config.BallotLimit))
+	default:
+		return false, "", nil
+	}
+
+	exceptionRepository := c.App.Metadata["exceptions"].(ExceptionStore)
+	remaining, err := exceptionRepository.Exceptions(c.Context, settings.WeekID, author)
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load exceptions.\n"))
+		return false, "", err
+	}
+
+	if remaining > 0 {
+		return true, "", nil
+	}
+
+	if len(own) > 0 {
+		outputBuffer.WriteString(" Your suggestions:\n")
+		for _, s := range own {
+			outputBuffer.WriteString(fmt.Sprintf("#%d %s\n", s.Order, s.Movie.String()))
+		}
+		outputBuffer.WriteString("Remove one with mov suggestions remove [id], or ask a moderator for an exception.\n")
+	} else {
+		outputBuffer.WriteString(" Ask a moderator for an exception.\n")
+	}
+
+	return false, outputBuffer.String(), nil
+}
+
+func useException(c *cli.Context, settings *general.AppSettings, author string) error {
+	exceptionRepository := c.App.Metadata["exceptions"].(ExceptionStore)
+	if _, err := exceptionRepository.UseException(c.Context, settings.WeekID, author); err != nil {
+		c.App.Writer.Write([]byte("Unable to use the exception.\n"))
+		return err
+	}
+
+	return nil
+}
+
+// checkWatched refuses movies watched within the rewatch cooldown, telling
+// the user when they can suggest it again. Movies watched before then are
+// allowed with a warning.
+func checkWatched(c *cli.Context, settings *general.AppSettings, s Suggestion) (warning string, refused bool, err error) {
+	entries, err := loadWatched(c)
+	if err != nil {
+		return "", false, err
+	}
+
+	watched, cooldown := InCooldown(settings.Config, settings.Now, entries, s)
+	warning, refusal := watchedMessage(settings, watched, cooldown)
+	if refusal != "" {
+		_, writeErr := c.App.Writer.Write([]byte(refusal))
+		return "", true, writeErr
+	}
+
+	return warning, false, nil
+}
+
+func loadWatched(c *cli.Context) ([]history.Entry, error) {
+	entries, err := c.App.Metadata["history"].(history.Store).Watched(c.Context)
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load the watch history.\n"))
+	}
+
+	return entries, err
+}
+
+// watchedMessage warns that the movie was already watched, or refuses it
+// when that was within the cooldown.
+func watchedMessage(settings *general.AppSettings, watched *history.Entry, cooldown bool) (warning string, refusal string) {
+	if watched == nil {
+		return "", ""
+	}
+
+	watchedOn := watched.Watched.In(&settings.Localization).Format("2006-01-02")
+	if cooldown {
+		available := watched.Available(settings.Config.RewatchCooldown)
+		return "", fmt.Sprintf("Sorry, \"%s\" was watched on %s. It can be suggested again after %s.\n",
+			watched.Movie.String(), watchedOn, available.In(&settings.Localization).Format("2006-01-02"))
+	}
+
+	return fmt.Sprintf("Heads up, \"%s\" was already watched on %s.\n", watched.Movie.String(), watchedOn), ""
+}
+
 func listMoviesAction(c *cli.Context) error {
 	settings := c.App.Metadata["settings"].(*general.AppSettings)
-	dbSession := c.App.Metadata["dbSession"].(*sql.DB)
+	suggestionRepository := c.App.Metadata["suggestions"].(Store)
 
-	suggestionRepository := NewRepository(dbSession)
+	suggestions, err := suggestionRepository.AllSuggestions(c.Context, settings.WeekID)
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load suggestions.\n"))
+		return err
+	}
+
+	seconds, err := c.App.Metadata["seconds"].(SecondStore).Seconds(c.Context, settings.WeekID)
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load seconds.\n"))
+		return err
+	}
+
+	// Once suggestions close the list is the ballot
+	threshold := settings.Config.NominationThreshold
+	if settings.CurPeriod.Name != general.Suggesting {
+		suggestions = Nominated(suggestions, seconds, threshold)
+	}
+
+	counts := CountSeconds(seconds)
+
+	movieRepository := c.App.Metadata["movies"].(metadata.MetadataProvider)
+	details := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		if s.MovieID == "" {
+			continue
+		}
+
+		record, err := movieRepository.Lookup(c.Context, s.MovieID)
+		if errors.Is(err, metadata.ErrUnknownMovie) {
+			continue
+		}
+
+		if err != nil {
+			c.App.Writer.Write([]byte("Unable to load movie details.\n"))
+			return err
+		}
+
+		details[i] = record.Details()
+	}
+
+	// Seconds are only counted towards a threshold
+	if threshold > 0 {
+		for i, s := range suggestions {
+			details[i] = strings.TrimRight(fmt.Sprintf("%-9s%s", fmt.Sprintf("%d/%d", counts[s.Order], threshold), details[i]), " ")
+		}
+	}
 
 	var outputBuffer strings.Builder
 
-	outputBuffer.WriteString(fmt.Sprintf("%-4s%-.32s\n", "ID", "Movie"))
+	header := detailsHeader(details)
+	if threshold > 0 {
+		header = fmt.Sprintf("%-9s%s", "Seconds", detailsHeader(details))
+	}
 
-	suggestionRepository.AllSuggestions(settings.WeekID, func(k []byte, s *Suggestion) error {
-		outputBuffer.WriteString(fmt.Sprintf("%-4d%-.32s\n",
+	outputBuffer.WriteString(strings.TrimRight(fmt.Sprintf("%-4s%-33.32s%s", "ID", "Movie", header), " ") + "\n")
+
+	for i, s := range suggestions {
+		outputBuffer.WriteString(strings.TrimRight(fmt.Sprintf("%-4d%-33.32s%s",
 			s.Order,
-			s.Movie.String()))
-		return nil
-	})
+			s.Movie.String(),
+			details[i]), " ") + "\n")
 
-	c.App.Writer.Write([]byte(outputBuffer.String()))
+		if c.Bool("pitches") && s.Pitch != "" {
+			outputBuffer.WriteString(fmt.Sprintf("    %s\n", s.Excerpt(excerptLength)))
+		}
+	}
 
-	return nil
+	_, writeErr := c.App.Writer.Write([]byte(outputBuffer.String()))
+	return writeErr
 }
 
-func removeMovieAction(c *cli.Context) error {
+func showMovieAction(c *cli.Context) error {
 	settings := c.App.Metadata["settings"].(*general.AppSettings)
-	dbSession := c.App.Metadata["dbSession"].(*sql.DB)
+	suggestionRepository := c.App.Metadata["suggestions"].(Store)
+	movieRepository := c.App.Metadata["movies"].(metadata.MetadataProvider)
+
+	orderID, err := strconv.ParseUint(c.Args().First(), 10, 64)
+	if err != nil {
+		_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("\"%s\" is not a number.\n", c.Args().First())))
+		return writeErr
+	}
+
+	found, err := suggestionRepository.GetSuggestionByOrder(c.Context, settings.WeekID, OrderedID(orderID))
+	if errors.Is(err, ErrUnknownSuggestion) {
+		_, writeErr := c.App.Writer.Write([]byte("Unable to find a matching suggestion.\n"))
+		return writeErr
+	}
+
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load the suggestion.\n"))
+		return err
+	}
 
-	suggestionRepository := NewRepository(dbSession)
+	var outputBuffer strings.Builder
+
+	outputBuffer.WriteString(fmt.Sprintf("#%d %s\n", found.Order, found.Movie.String()))
+	outputBuffer.WriteString(fmt.Sprintf("Suggested by %s\n", found.Author))
+
+	if found.MovieID != "" {
+		record, err := movieRepository.Lookup(c.Context, found.MovieID)
+		if err != nil && !errors.Is(err, metadata.ErrUnknownMovie) {
+			c.App.Writer.Write([]byte("Unable to load movie details.\n"))
+			return err
+		}
+
+		if err == nil && record.Details() != "" {
+			outputBuffer.WriteString(record.Details() + "\n")
+		}
+	}
+
+	seconds, err := c.App.Metadata["seconds"].(SecondStore).Seconds(c.Context, settings.WeekID)
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load seconds.\n"))
+		return err
+	}
+
+	var seconders []string
+	for _, second := range seconds {
+		if second.Order == found.Order {
+			seconders = append(seconders, second.User)
+		}
+	}
+
+	renames, err := suggestionRepository.Renames(c.Context, *found)
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load previous titles.\n"))
+		return err
+	}
+
+	for _, rename := range renames {
+		outputBuffer.WriteString(fmt.Sprintf("Renamed from \"%s\" by %s on %s\n",
+			rename.Movie.String(), rename.By, rename.Renamed.In(&settings.Localization).Format("2006-01-02")))
+	}
+
+	if len(seconders) > 0 {
+		outputBuffer.WriteString(fmt.Sprintf("Seconded by %s\n", strings.Join(seconders, ", ")))
+	}
+
+	if found.Link != "" {
+		outputBuffer.WriteString(fmt.Sprintf("Link: %s\n", found.Link))
+	}
+
+	if found.Pitch != "" {
+		outputBuffer.WriteString("\n" + found.Pitch + "\n")
+	}
+
+	_, writeErr := c.App.Writer.Write([]byte(outputBuffer.String()))
+	return writeErr
+}
+
+func secondMovieAction(c *cli.Context) error {
+	settings := c.App.Metadata["settings"].(*general.AppSettings)
+	suggestionRepository := c.App.Metadata["suggestions"].(Store)
+	secondRepository := c.App.Metadata["seconds"].(SecondStore)
+	user := c.String("user")
 
 	orderID, err := strconv.ParseUint(c.Args().First(), 10, 64)
 	if err != nil {
@@ -117,29 +568,482 @@ func removeMovieAction(c *cli.Context) error {
 		return writeErr
 	}
 
-	if settings.CurPeriod.Name != general.Suggesting && !c.Bool("bypass") {
-		_, writeErr := c.App.Writer.Write([]byte("Sorry, unable to remove the movie from suggestions. The suggestion period has already ended.\n"))
+	ignorePeriods, authErr := auth.Allowed(c, auth.IgnorePeriods)
+	if authErr != nil {
+		return authErr
+	}
+
+	if settings.CurPeriod.Name != general.Suggesting && !ignorePeriods {
+		_, writeErr := c.App.Writer.Write([]byte("Sorry, unable to second the movie. " + settings.ClosedReason(general.Suggesting) + "\n"))
 		return writeErr
 	}
 
-	// Need to first get a suggestion
-	foundSuggestion := suggestionRepository.GetSuggestionByOrder(OrderedID(orderID))
-	if foundSuggestion == nil {
-		_, _ = c.App.Writer.Write([]byte("Unable to find a matching suggestion.\n"))
-		return nil
+	found, err := suggestionRepository.GetSuggestionByOrder(c.Context, settings.WeekID, OrderedID(orderID))
+	if errors.Is(err, ErrUnknownSuggestion) {
+		_, writeErr := c.App.Writer.Write([]byte("Unable to find a matching suggestion.\n"))
+		return writeErr
 	}
 
-	// Compare suggestion authors to validate this user can remove suggestion
-	if strings.Compare(foundSuggestion.Author, c.String("user")) != 0 {
-		_, writeErr := c.App.Writer.Write([]byte("You did not suggest this movie, and can't remove it.\n"))
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load the suggestion.\n"))
+		return err
+	}
+
+	if found.Author == user {
+		_, writeErr := c.App.Writer.Write([]byte("You can't second your own suggestion.\n"))
+		return writeErr
+	}
+
+	saveErr := secondRepository.SaveSecond(c.Context, Second{WeekID: settings.WeekID, Order: found.Order, User: user})
+	if errors.Is(saveErr, ErrAlreadySeconded) {
+		_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("You already seconded #%d %s.\n", found.Order, found.Movie.String())))
+		return writeErr
+	}
+
+	if saveErr != nil {
+		c.App.Writer.Write([]byte("Unable to save the second.\n"))
+		return saveErr
+	}
+
+	output := fmt.Sprintf("Seconded #%d %s.\n", found.Order, found.Movie.String())
+	if threshold := settings.Config.NominationThreshold; threshold > 0 {
+		seconds, err := secondRepository.Seconds(c.Context, settings.WeekID)
+		if err != nil {
+			c.App.Writer.Write([]byte("Unable to load seconds.\n"))
+			return err
+		}
+
+		count := CountSeconds(seconds)[found.Order]
+		if count < threshold {
+			output = fmt.Sprintf("Seconded #%d %s, it has %d of the %d seconds it needs to make the ballot.\n",
+				found.Order, found.Movie.String(), count, threshold)
+		} else {
+			output = fmt.Sprintf("Seconded #%d %s, it made the ballot.\n", found.Order, found.Movie.String())
+		}
+	}
+
+	_, writeErr := c.App.Writer.Write([]byte(output))
+	return writeErr
+}
+
+func carryMoviesAction(c *cli.Context) error {
+	settings := c.App.Metadata["settings"].(*general.AppSettings)
+	suggestionRepository := c.App.Metadata["suggestions"].(Store)
+	author := c.String("user")
+
+	from := settings.WeekID.Previous()
+	if c.IsSet("week") {
+		parsedWeek, parseErr := general.WeekIDFromString(c.String("week"))
+		if parseErr != nil {
+			_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("\"%s\" is not a valid week. Use the format YYYYWW.\n", c.String("week"))))
+			return writeErr
+		}
+		from = *parsedWeek
+	}
+
+	if from == settings.WeekID {
+		_, writeErr := c.App.Writer.Write([]byte("Suggestions can only be carried from a past week.\n"))
+		return writeErr
+	}
+
+	ignorePeriods, authErr := auth.Allowed(c, auth.IgnorePeriods)
+	if authErr != nil {
+		return authErr
+	}
+
+	if settings.CurPeriod.Name != general.Suggesting && !ignorePeriods {
+		_, writeErr := c.App.Writer.Write([]byte("Sorry, unable to carry suggestions. " + settings.ClosedReason(general.Suggesting) + "\n"))
+		return writeErr
+	}
+
+	past, err := suggestionRepository.AllSuggestions(c.Context, from)
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load suggestions.\n"))
+		return err
+	}
+
+	selected := map[OrderedID]bool{}
+	for i := 0; i < c.NArg(); i++ {
+		orderID, parseErr := strconv.ParseUint(c.Args().Get(i), 10, 64)
+		if parseErr != nil {
+			_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("\"%s\" is not a number.\n", c.Args().Get(i))))
+			return writeErr
+		}
+		selected[OrderedID(orderID)] = true
+	}
+
+	current, err := suggestionRepository.AllSuggestions(c.Context, settings.WeekID)
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load suggestions.\n"))
+		return err
+	}
+
+	entries, err := loadWatched(c)
+	if err != nil {
+		return err
+	}
+
+	var outputBuffer strings.Builder
+	carried := 0
+	for _, s := range past {
+		if len(selected) > 0 && !selected[s.Order] {
+			continue
+		}
+
+		if s.Author != author {
+			if selected[s.Order] {
+				outputBuffer.WriteString(fmt.Sprintf("You did not suggest #%d %s, and can't carry it.\n", s.Order, s.Movie.String()))
+			}
+			continue
+		}
+
+		delete(selected, s.Order)
+
+		copied, err := Carry(s, settings.WeekID)
+		if err != nil {
+			return err
+		}
+
+		eligibility := Eligible(settings.Config, settings.Now, current, entries, *copied)
+		watchedWarning, refusal := watchedMessage(settings, eligibility.Watched, eligibility.Cooldown)
+		if refusal != "" {
+			outputBuffer.WriteString(refusal)
+			continue
+		}
+
+		exception, refusal, err := checkLimits(c, settings, eligibility.Limit, current, author)
+		if err != nil {
+			return err
+		}
+
+		if refusal != "" {
+			outputBuffer.WriteString(refusal)
+			break
+		}
+
+		saveErr := suggestionRepository.Save(c.Context, *copied)
+		if errors.Is(saveErr, ErrDuplicateMovie) {
+			outputBuffer.WriteString(fmt.Sprintf("Movie \"%s\" was already suggested.\n", s.Movie.String()))
+			continue
+		}
+
+		if saveErr != nil {
+			c.App.Writer.Write([]byte(outputBuffer.String() + "Unable to save the suggestion.\n"))
+			return saveErr
+		}
+
+		if exception {
+			if err := useException(c, settings, author); err != nil {
+				return err
+			}
+		}
+
+		current = append(current, *copied)
+
+		outputBuffer.WriteString(watchedWarning)
+		outputBuffer.WriteString(fmt.Sprintf("Carried \"%s\" over from week %s.\n", s.Movie.String(), from))
+		carried++
+	}
+
+	for orderID := range selected {
+		if !hasOrder(past, orderID) {
+			outputBuffer.WriteString(fmt.Sprintf("Week %s has no suggestion %d.\n", from, orderID))
+		}
+	}
+
+	if carried == 0 && outputBuffer.Len() == 0 {
+		outputBuffer.WriteString(fmt.Sprintf("You have no suggestions in week %s to carry.\n", from))
+	}
+
+	_, writeErr := c.App.Writer.Write([]byte(outputBuffer.String()))
+	return writeErr
+}
+
+func hasOrder(suggestions []Suggestion, orderID OrderedID) bool {
+	for _, s := range suggestions {
+		if s.Order == orderID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func editMovieAction(c *cli.Context) error {
+	settings := c.App.Metadata["settings"].(*general.AppSettings)
+	suggestionRepository := c.App.Metadata["suggestions"].(Store)
+	user := c.String("user")
+
+	if c.NArg() < 2 {
+		_, writeErr := c.App.Writer.Write([]byte("Suggestion ID and new movie name not provided as arguments.\n"))
+		return writeErr
+	}
+
+	orderID, err := strconv.ParseUint(c.Args().First(), 10, 64)
+	if err != nil {
+		_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("\"%s\" is not a number.\n", c.Args().First())))
+		return writeErr
+	}
+
+	ignorePeriods, authErr := auth.Allowed(c, auth.IgnorePeriods)
+	if authErr != nil {
+		return authErr
+	}
+
+	// Titles stay editable while voting so typos can be fixed, but only
+	// moderators may change what was voted for, see below
+	open := settings.CurPeriod.Name == general.Suggesting || settings.CurPeriod.Name == general.Voting
+	if !open && !ignorePeriods {
+		_, writeErr := c.App.Writer.Write([]byte("Sorry, unable to edit the movie. " + settings.ClosedReason(general.Voting) + "\n"))
+		return writeErr
+	}
+
+	found, err := suggestionRepository.GetSuggestionByOrder(c.Context, settings.WeekID, OrderedID(orderID))
+	if errors.Is(err, ErrUnknownSuggestion) {
+		_, writeErr := c.App.Writer.Write([]byte("Unable to find a matching suggestion.\n"))
+		return writeErr
+	}
+
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load the suggestion.\n"))
+		return err
+	}
+
+	if found.Author != user {
+		moderator, authErr := auth.Allowed(c, auth.EditAnySuggestion)
+		if authErr != nil {
+			return authErr
+		}
+
+		if !moderator {
+			_, writeErr := c.App.Writer.Write([]byte("You did not suggest this movie, and can't edit it.\n"))
+			return writeErr
+		}
+	}
+
+	title := c.Args().Get(1)
+	movie := general.MovieFromString(title)
+	record, resolved, err := resolveMovie(c, title)
+	if err != nil || !resolved {
+		return err
+	}
+
+	edited := *found
+	edited.MovieID = ""
+	if record != nil {
+		movie = general.MovieFromString(record.String())
+		edited.MovieID = record.ID
+	}
+
+	if errors.Is(CheckTitle(movie), ErrUntitledMovie) {
+		return writeUntitled(c, title)
+	}
+
+	if settings.CurPeriod.Name == general.Voting && !ignorePeriods && !sameMovie(found.Movie, movie) {
+		moderator, authErr := auth.Allowed(c, auth.EditAnySuggestion)
+		if authErr != nil {
+			return authErr
+		}
+
+		if !moderator {
+			_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("Votes were cast for \"%s\", while voting only its spelling may be fixed.\n", found.Movie.String())))
+			return writeErr
+		}
+	}
+
+	edited.Movie = movie
+
+	watchedWarning, refused, err := checkWatched(c, settings, edited)
+	if err != nil || refused {
+		return err
+	}
+
+	renameErr := suggestionRepository.Rename(c.Context, edited, user)
+	if errors.Is(renameErr, ErrDuplicateMovie) {
+		c.App.Writer.Write([]byte(fmt.Sprintf("Movie \"%s\" was already suggested.\n", edited.Movie.String())))
+		return renameErr
+	}
+
+	if renameErr != nil {
+		c.App.Writer.Write([]byte("Unable to save the suggestion.\n"))
+		return renameErr
+	}
+
+	_, writeErr := c.App.Writer.Write([]byte(watchedWarning + fmt.Sprintf("Renamed #%d from \"%s\" to \"%s\".\n",
+		found.Order, found.Movie.String(), edited.Movie.String())))
+	return writeErr
+}
+
+func removeMovieAction(c *cli.Context) error {
+	settings := c.App.Metadata["settings"].(*general.AppSettings)
+	suggestionRepository := c.App.Metadata["suggestions"].(Store)
+
+	orderID, err := strconv.ParseUint(c.Args().First(), 10, 64)
+	if err != nil {
+		_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("\"%s\" is not a number.\n", c.Args().First())))
+		return writeErr
+	}
+
+	ignorePeriods, authErr := auth.Allowed(c, auth.IgnorePeriods)
+	if authErr != nil {
+		return authErr
+	}
+
+	if settings.CurPeriod.Name != general.Suggesting && !ignorePeriods {
+		_, writeErr := c.App.Writer.Write([]byte("Sorry, unable to remove the movie from suggestions. " + settings.ClosedReason(general.Suggesting) + "\n"))
 		return writeErr
 	}
 
+	// Suggestions are numbered per week, only this week's can be removed
+	foundSuggestion, err := suggestionRepository.GetSuggestionByOrder(c.Context, settings.WeekID, OrderedID(orderID))
+	if errors.Is(err, ErrUnknownSuggestion) {
+		_, writeErr := c.App.Writer.Write([]byte("Unable to find a matching suggestion.\n"))
+		return writeErr
+	}
+
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load the suggestion.\n"))
+		return err
+	}
+
+	// Compare suggestion authors to validate this user can remove suggestion,
+	// moderators may remove anyone's
+	if strings.Compare(foundSuggestion.Author, c.String("user")) != 0 {
+		moderator, authErr := auth.Allowed(c, auth.RemoveAnySuggestion)
+		if authErr != nil {
+			return authErr
+		}
+
+		if !moderator {
+			_, writeErr := c.App.Writer.Write([]byte("You did not suggest this movie, and can't remove it.\n"))
+			return writeErr
+		}
+	}
+
 	// Remove suggestion
-	if removeErr := suggestionRepository.Remove(*foundSuggestion); removeErr != nil {
+	if removeErr := suggestionRepository.Remove(c.Context, *foundSuggestion); removeErr != nil {
 		_, _ = c.App.Writer.Write([]byte("Unable to remove suggestion from DB.\n"))
 		return removeErr
 	}
 
 	return nil
 }
+
+func grantExceptionAction(c *cli.Context) error {
+	settings := c.App.Metadata["settings"].(*general.AppSettings)
+	exceptionRepository := c.App.Metadata["exceptions"].(ExceptionStore)
+
+	if allowed, err := auth.Require(c, auth.GrantExceptions); !allowed {
+		return err
+	}
+
+	user := c.Args().First()
+	if user == "" {
+		_, writeErr := c.App.Writer.Write([]byte("User not provided as argument.\n"))
+		return writeErr
+	}
+
+	if err := exceptionRepository.GrantException(c.Context, settings.WeekID, user, c.String("user")); err != nil {
+		c.App.Writer.Write([]byte("Unable to grant the exception.\n"))
+		return err
+	}
+
+	_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("%s may add one more suggestion this week.\n", user)))
+	return writeErr
+}
+
+// findSimilar returns the first suggestion that is likely the same movie.
+// Exact matches are left for Save to refuse.
+func findSimilar(suggestions []Suggestion, movie general.Movie) *Suggestion {
+	for i, s := range suggestions {
+		if s.Movie.Encode() != movie.Encode() && s.Movie.Similar(movie) {
+			return &suggestions[i]
+		}
+	}
+
+	return nil
+}
+
+// sameMovie tells if an edit only fixes the title, such as "Shrke" to "Shrek"
+// or "Shrek" to its catalog name "Shrek (2001)".
+func sameMovie(movie general.Movie, edited general.Movie) bool {
+	title, year := metadata.ParseQuery(movie.String())
+	editedTitle, editedYear := metadata.ParseQuery(edited.String())
+	if year != 0 && editedYear != 0 && year != editedYear {
+		return false
+	}
+
+	return title.Similar(editedTitle)
+}
+
+// resolveMovie finds the catalog record of the title, or of the --movie id.
+// The record is nil when the catalog doesn't know the title. Only a movie
+// titled as written is used on its own, one titled differently, such as
+// "Spider-Man" for "spiderman", has to be confirmed. When the movie can't be
+// resolved, because several share the title, it needs confirming or the id
+// is unknown, the user is told and resolved is false.
+func resolveMovie(c *cli.Context, title string) (record *metadata.Record, resolved bool, err error) {
+	movieRepository := c.App.Metadata["movies"].(metadata.MetadataProvider)
+
+	if c.IsSet("movie") {
+		record, err := movieRepository.Lookup(c.Context, c.String("movie"))
+		if errors.Is(err, metadata.ErrUnknownMovie) {
+			_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("Movie %s is not in the catalog, try: mov movies search \"%s\"\n", c.String("movie"), title)))
+			return nil, false, writeErr
+		}
+
+		if err != nil {
+			c.App.Writer.Write([]byte("Unable to search the movie catalog.\n"))
+			return nil, false, err
+		}
+
+		return record, true, nil
+	}
+
+	records, err := movieRepository.Search(c.Context, title)
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to search the movie catalog.\n"))
+		return nil, false, err
+	}
+
+	var exact []metadata.Record
+	for _, r := range records {
+		if r.TitledAs(title) {
+			exact = append(exact, r)
+		}
+	}
+
+	if len(exact) == 1 {
+		return &exact[0], true, nil
+	}
+
+	if len(records) == 0 || (len(exact) == 0 && c.Bool("as-written")) {
+		return nil, true, nil
+	}
+
+	// Repeat the command with the arguments before the title, such as the
+	// id of the suggestion being edited
+	preceding := c.Args().Slice()[:c.NArg()-1]
+	command := append([]string{"mov", "suggestions", c.Command.Name, "--movie", "[id]"}, preceding...)
+
+	if len(exact) == 0 {
+		asWritten := append([]string{"mov", "suggestions", c.Command.Name, "--as-written"}, preceding...)
+		_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("Did you mean one of these? Pick one with: %s \"%s\", or keep \"%s\" with: %s \"%s\"\n%s",
+			strings.Join(command, " "), title, title, strings.Join(asWritten, " "), title, metadata.FormatRecords(records))))
+		return nil, false, writeErr
+	}
+
+	_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("Several movies are titled \"%s\", pick one with: %s \"%s\"\n%s",
+		title, strings.Join(command, " "), title, metadata.FormatRecords(exact))))
+	return nil, false, writeErr
+}
+
+func detailsHeader(details []string) string {
+	for _, d := range details {
+		if d != "" {
+			return "Details"
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,388 @@
+package suggestion_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/auth"
+	"github.com/fredlawl/200-colony-movie-night-bot/metadata"
+	"github.com/fredlawl/200-colony-movie-night-bot/movtest"
+)
+
+func TestGivenASuggestionItIsListed(t *testing.T) {
+	h := movtest.New(t)
+
+	h.MustRun("liam", `suggestions add "Winnie the Pooh"`)
+	output := h.MustRun("noah", "suggestions list")
+
+	if !strings.Contains(output, "1   Winnie the Pooh") {
+		t.Fail()
+	}
+}
+
+func TestGivenTheSameMovieTwiceTheSecondIsRefused(t *testing.T) {
+	h := movtest.New(t)
+
+	h.MustRun("liam", "suggestions add Shrek")
+	output, err := h.Run("noah", "suggestions add shrek")
+
+	if err == nil || !strings.Contains(output, "already suggested") {
+		t.Fail()
+	}
+}
+
+func TestGivenTheVotingPeriodSuggestionsAreClosed(t *testing.T) {
+	h := movtest.New(t)
+	h.Weekday(time.Thursday)
+
+	output := h.MustRun("liam", "suggestions add Shrek")
+
+	if !strings.Contains(output, "unable to add the movie") || strings.Contains(h.MustRun("liam", "suggestions list"), "Shrek") {
+		t.Fail()
+	}
+}
+
+func TestGivenAnotherUsersSuggestionAMemberCantRemoveIt(t *testing.T) {
+	h := movtest.New(t)
+
+	h.MustRun("liam", "suggestions add Shrek")
+	output := h.MustRun("noah", "suggestions remove 1")
+
+	if !strings.Contains(output, "can't remove it") || !strings.Contains(h.MustRun("noah", "suggestions list"), "Shrek") {
+		t.Fail()
+	}
+}
+
+func TestGivenARemovedSuggestionTheOthersKeepTheirNumbers(t *testing.T) {
+	h := movtest.New(t)
+	h.MustRun("liam", "suggestions add Shrek")
+	h.MustRun("liam", `suggestions add "Winnie the Pooh"`)
+	h.MustRun("liam", "suggestions add Cars")
+
+	h.MustRun("liam", "suggestions remove 2")
+	h.MustRun("liam", "suggestions add Up")
+	output := h.MustRun("liam", "suggestions list")
+
+	if !strings.Contains(output, "1   Shrek") || !strings.Contains(output, "3   Cars") || !strings.Contains(output, "4   Up") || strings.Contains(output, "Pooh") {
+		t.Fail()
+	}
+}
+
+func TestGivenALikelyDuplicateItAsksForForce(t *testing.T) {
+	h := movtest.New(t)
+	h.MustRun("liam", `suggestions add "Shrek 2"`)
+
+	output := h.MustRun("noah", `suggestions add "Shrek II"`)
+
+	if !strings.Contains(output, "Did you mean #1 Shrek 2?") || strings.Contains(h.MustRun("noah", "suggestions list"), "Shrek II") {
+		t.Fail()
+	}
+}
+
+func TestGivenForceALikelyDuplicateIsAdded(t *testing.T) {
+	h := movtest.New(t)
+	h.MustRun("liam", "suggestions add Shrek")
+
+	h.MustRun("noah", "suggestions add --force Shreck")
+
+	if !strings.Contains(h.MustRun("noah", "suggestions list"), "2   Shreck") {
+		t.Fail()
+	}
+}
+
+func TestGivenSeveralCatalogMatchesTheUserIsAskedToPick(t *testing.T) {
+	h := movtest.New(t)
+	h.Database.Movies().Import(context.Background(), []metadata.Record{
+		{ID: "tt0087182", Title: "Dune", Year: 1984},
+		{ID: "tt1160419", Title: "Dune", Year: 2021, Runtime: 155 * time.Minute},
+	})
+
+	output := h.MustRun("liam", "suggestions add Dune")
+	h.MustRun("liam", "suggestions add --movie tt1160419 Dune")
+	list := h.MustRun("liam", "suggestions list")
+
+	if !strings.Contains(output, "tt0087182") || !strings.Contains(output, "tt1160419") || !strings.Contains(list, "1   Dune (2021)") || !strings.Contains(list, "2h 35m") {
+		t.Fail()
+	}
+}
+
+func TestGivenASingleCatalogMatchItIsNamedWithItsYear(t *testing.T) {
+	h := movtest.New(t)
+	h.Database.Movies().Import(context.Background(), []metadata.Record{{ID: "tt0126029", Title: "Shrek", Year: 2001}})
+
+	h.MustRun("liam", "suggestions add shrek")
+
+	if !strings.Contains(h.MustRun("liam", "suggestions list"), "1   Shrek (2001)") {
+		t.Fail()
+	}
+}
+
+func TestGivenACatalogMatchTitledDifferentlyTheUserIsAskedToConfirm(t *testing.T) {
+	h := movtest.New(t)
+	h.Database.Movies().Import(context.Background(), []metadata.Record{{ID: "tt0145487", Title: "Spider-Man", Year: 2002}})
+
+	output := h.MustRun("liam", "suggestions add spiderman")
+	empty := h.MustRun("liam", "suggestions list")
+	h.MustRun("liam", "suggestions add --as-written spiderman")
+	list := h.MustRun("liam", "suggestions list")
+
+	if !strings.Contains(output, "Did you mean") || !strings.Contains(output, "tt0145487") || strings.Contains(empty, "Spider") || !strings.Contains(list, "1   spiderman") || strings.Contains(list, "2002") {
+		t.Fail()
+	}
+}
+
+func TestGivenATitleDifferingInVowelsTheCatalogMovieIsNotUsed(t *testing.T) {
+	h := movtest.New(t)
+	h.Database.Movies().Import(context.Background(), []metadata.Record{{ID: "tt1160419", Title: "Dune", Year: 2021}})
+
+	h.MustRun("liam", "suggestions add Done")
+
+	if !strings.Contains(h.MustRun("liam", "suggestions list"), "1   Done") {
+		t.Fail()
+	}
+}
+
+func TestGivenLastWeeksSuggestionsCarryCopiesTheUsersOwn(t *testing.T) {
+	h := movtest.New(t)
+	h.MustRun("liam", "suggestions add Shrek")
+	h.MustRun("noah", "suggestions add Cars")
+	h.MustRun("liam", "suggestions add Up")
+	h.Clock.Advance(7 * 24 * time.Hour)
+
+	output := h.MustRun("liam", "suggestions carry")
+	list := h.MustRun("liam", "suggestions list")
+
+	if !strings.Contains(output, `Carried "Shrek" over from week 202114.`) || !strings.Contains(list, "1   Shrek") || !strings.Contains(list, "2   Up") || strings.Contains(list, "Cars") {
+		t.Fail()
+	}
+}
+
+func TestGivenSomeoneElsesSuggestionCarryRefusesIt(t *testing.T) {
+	h := movtest.New(t)
+	h.MustRun("noah", "suggestions add Cars")
+	h.Clock.Advance(7 * 24 * time.Hour)
+
+	output := h.MustRun("liam", "suggestions carry 1")
+
+	if !strings.Contains(output, "You did not suggest #1 Cars") || strings.Contains(h.MustRun("liam", "suggestions list"), "Cars") {
+		t.Fail()
+	}
+}
+
+func TestGivenTheWeeklyLimitTheUserIsShownTheirSuggestions(t *testing.T) {
+	h := movtest.New(t)
+	h.Config.SuggestionLimit = 2
+	h.MustRun("liam", "suggestions add Shrek")
+	h.MustRun("liam", "suggestions add Cars")
+
+	output := h.MustRun("liam", "suggestions add Up")
+
+	if !strings.Contains(output, "only suggest 2 movies a week") || !strings.Contains(output, "#1 Shrek\n#2 Cars\n") || strings.Contains(h.MustRun("liam", "suggestions list"), "Up") {
+		t.Fail()
+	}
+}
+
+func TestGivenAFullBallotSuggestionsAreRefused(t *testing.T) {
+	h := movtest.New(t)
+	h.Config.BallotLimit = 1
+	h.MustRun("liam", "suggestions add Shrek")
+
+	output := h.MustRun("noah", "suggestions add Cars")
+
+	if !strings.Contains(output, "ballot is full with 1 movies") || strings.Contains(h.MustRun("noah", "suggestions list"), "Cars") {
+		t.Fail()
+	}
+}
+
+func TestGivenAnExceptionTheUserMayAddOneMoreSuggestion(t *testing.T) {
+	h := movtest.New(t)
+	h.Config.SuggestionLimit = 1
+	h.Database.Users().SetRole(context.Background(), "olivia", auth.Moderator)
+	h.MustRun("liam", "suggestions add Shrek")
+
+	if output := h.MustRun("liam", "suggestions grant liam"); !strings.Contains(output, "need to be a moderator") {
+		t.Fatalf("expected members to be refused, got %q", output)
+	}
+
+	h.MustRun("olivia", "suggestions grant liam")
+	h.MustRun("liam", "suggestions add Cars")
+	output := h.MustRun("liam", "suggestions add Up")
+	list := h.MustRun("liam", "suggestions list")
+
+	if !strings.Contains(list, "2   Cars") || strings.Contains(list, "Up") || !strings.Contains(output, "only suggest 1 movies a week") {
+		t.Fail()
+	}
+}
+
+func TestGivenAPitchShowPrintsTheWholeCard(t *testing.T) {
+	h := movtest.New(t)
+	h.MustRun("liam", `suggestions add --pitch "An ogre rescues a princess.
+Also, Smash Mouth." --link https://example.com/shrek Shrek`)
+
+	output := h.MustRun("noah", "suggestions show 1")
+
+	if !strings.Contains(output, "#1 Shrek\nSuggested by liam\nLink: https://example.com/shrek\n\nAn ogre rescues a princess.\nAlso, Smash Mouth.\n") {
+		t.Fail()
+	}
+}
+
+func TestGivenPitchesTheListIncludesExcerpts(t *testing.T) {
+	h := movtest.New(t)
+	h.MustRun("liam", `suggestions add --pitch "An ogre rescues a princess.
+Also, Smash Mouth." Shrek`)
+	h.MustRun("noah", "suggestions add Cars")
+
+	output := h.MustRun("noah", "suggestions list --pitches")
+
+	if !strings.Contains(output, "1   Shrek\n    An ogre rescues a princess....\n2   Cars\n") || strings.Contains(h.MustRun("noah", "suggestions list"), "ogre") {
+		t.Fail()
+	}
+}
+
+func TestGivenAnInvalidLinkTheSuggestionIsRefused(t *testing.T) {
+	h := movtest.New(t)
+
+	output := h.MustRun("liam", "suggestions add --link example.com Shrek")
+
+	if !strings.Contains(output, "not a valid link") || strings.Contains(h.MustRun("liam", "suggestions list"), "Shrek") {
+		t.Fail()
+	}
+}
+
+func TestGivenASecondTheListCountsItTowardsTheThreshold(t *testing.T) {
+	h := movtest.New(t)
+	h.Config.NominationThreshold = 2
+	h.MustRun("liam", "suggestions add Shrek")
+
+	own := h.MustRun("liam", "suggestions second 1")
+	output := h.MustRun("noah", "suggestions second 1")
+	again := h.MustRun("noah", "suggestions second 1")
+	list := h.MustRun("noah", "suggestions list")
+
+	if !strings.Contains(own, "can't second your own") || !strings.Contains(output, "1 of the 2 seconds") || !strings.Contains(again, "already seconded") || !strings.Contains(list, "1   Shrek                            1/2") {
+		t.Fail()
+	}
+}
+
+func TestGivenSuggestionsClosedTheListOnlyShowsTheBallot(t *testing.T) {
+	h := movtest.New(t)
+	h.Config.NominationThreshold = 1
+	h.MustRun("liam", "suggestions add Shrek")
+	h.MustRun("liam", "suggestions add Cars")
+	h.MustRun("noah", "suggestions second 2")
+
+	h.Weekday(time.Thursday)
+	output := h.MustRun("noah", "suggestions list")
+
+	if strings.Contains(output, "Shrek") || !strings.Contains(output, "2   Cars") || !strings.Contains(h.MustRun("noah", "suggestions second 1"), "unable to second") {
+		t.Fail()
+	}
+}
+
+func TestGivenAnEditTheSuggestionKeepsItsNumberAndPreviousTitle(t *testing.T) {
+	h := movtest.New(t)
+	h.MustRun("liam", "suggestions add Shrke")
+	h.MustRun("liam", "suggestions add Cars")
+
+	output := h.MustRun("liam", "suggestions edit 1 Shrek")
+	list := h.MustRun("noah", "suggestions list")
+	shown := h.MustRun("noah", "suggestions show 1")
+
+	if !strings.Contains(output, `Renamed #1 from "Shrke" to "Shrek".`) || !strings.Contains(list, "1   Shrek") || !strings.Contains(shown, `Renamed from "Shrke" by liam on 2021-04-05`) {
+		t.Fail()
+	}
+}
+
+func TestGivenAnotherUsersSuggestionAMemberCantEditIt(t *testing.T) {
+	h := movtest.New(t)
+	h.MustRun("liam", "suggestions add Shrke")
+
+	output := h.MustRun("noah", "suggestions edit 1 Shrek")
+
+	if !strings.Contains(output, "can't edit it") || !strings.Contains(h.MustRun("noah", "suggestions list"), "Shrke") {
+		t.Fail()
+	}
+}
+
+func TestGivenTheVotingPeriodAnEditToAnotherMovieIsRefused(t *testing.T) {
+	h := movtest.New(t)
+	h.MustRun("liam", "suggestions add Shrke")
+	h.Weekday(time.Thursday)
+
+	output := h.MustRun("liam", "suggestions edit 1 Cars")
+	fixed := h.MustRun("liam", "suggestions edit 1 Shrek")
+	list := h.MustRun("liam", "suggestions list")
+
+	if !strings.Contains(output, "only its spelling may be fixed") || !strings.Contains(fixed, `Renamed #1 from "Shrke" to "Shrek".`) || !strings.Contains(list, "1   Shrek") || strings.Contains(list, "Cars") {
+		t.Fail()
+	}
+}
+
+func TestGivenTheVotingPeriodAModeratorMayEditToAnotherMovie(t *testing.T) {
+	h := movtest.New(t)
+	h.Database.Users().SetRole(context.Background(), "olivia", auth.Moderator)
+	h.MustRun("liam", "suggestions add Shrek")
+	h.Weekday(time.Thursday)
+
+	h.MustRun("olivia", "suggestions edit 1 Cars")
+
+	if !strings.Contains(h.MustRun("liam", "suggestions list"), "1   Cars") {
+		t.Fail()
+	}
+}
+
+func TestGivenAnEditToAnAlreadySuggestedMovieItIsRefused(t *testing.T) {
+	h := movtest.New(t)
+	h.MustRun("liam", "suggestions add Shrek")
+	h.MustRun("liam", "suggestions add Cars")
+
+	output, err := h.Run("liam", "suggestions edit 2 shrek")
+	shown := h.MustRun("liam", "suggestions show 2")
+
+	if err == nil || !strings.Contains(output, "already suggested") || !strings.Contains(shown, "#2 Cars") || strings.Contains(shown, "Renamed") {
+		t.Fail()
+	}
+}
+
+func TestGivenAWatchedSuggestionCarryRefusesItAndCarriesTheRest(t *testing.T) {
+	h := movtest.New(t)
+	h.Config.Admins = []string{"olivia"}
+	h.MustRun("liam", "suggestions add Shrek")
+	h.MustRun("liam", "suggestions add Cars")
+	h.Weekday(time.Thursday)
+	h.MustRun("liam", "votes cast 1")
+	h.Weekday(time.Friday)
+	h.MustRun("olivia", "votes record")
+	h.Clock.Advance(3 * 24 * time.Hour)
+
+	output := h.MustRun("liam", "suggestions carry")
+	list := h.MustRun("liam", "suggestions list")
+
+	if !strings.Contains(output, `Sorry, "Shrek" was watched on 2021-04-09.`) || !strings.Contains(output, `Carried "Cars" over`) || strings.Contains(list, "Shrek") || !strings.Contains(list, "1   Cars") {
+		t.Fail()
+	}
+}
+
+func TestGivenAnUnknownSuggestionRemoveSaysItWasNotFound(t *testing.T) {
+	h := movtest.New(t)
+
+	output := h.MustRun("liam", "suggestions remove 7")
+
+	if !strings.Contains(output, "Unable to find a matching suggestion.") {
+		t.Fail()
+	}
+}
+
+func TestGivenATitleWithoutLettersOrDigitsItIsRefused(t *testing.T) {
+	h := movtest.New(t)
+	h.MustRun("liam", "suggestions add Shrek")
+
+	added := h.MustRun("liam", `suggestions add "?!"`)
+	edited := h.MustRun("liam", `suggestions edit 1 "..."`)
+
+	if !strings.Contains(added, `"?!" is not a valid title.`) || !strings.Contains(edited, `"..." is not a valid title.`) || !strings.Contains(h.MustRun("liam", "suggestions list"), "Shrek") {
+		t.Fail()
+	}
+}
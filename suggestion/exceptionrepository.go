@@ -0,0 +1,65 @@
+package suggestion
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+)
+
+// ExceptionRepository is the SQL ExceptionStore, for SQLite and PostgreSQL.
+type ExceptionRepository struct {
+	session *storage.DB
+}
+
+func NewExceptionRepository(session *storage.DB) *ExceptionRepository {
+	return &ExceptionRepository{
+		session: session,
+	}
+}
+
+// GrantException adds to the exceptions the user has left, so granting twice
+// allows two more suggestions.
+func (context *ExceptionRepository) GrantException(ctx context.Context, weekID general.WeekID, user string, grantedBy string) error {
+	_, err := context.session.ExecContext(ctx, `
+		INSERT INTO suggestion_exceptions (weekID, name, remaining, grantedBy)
+		VALUES (?, ?, 1, ?)
+		ON CONFLICT(weekID, name) DO UPDATE SET
+			remaining = suggestion_exceptions.remaining + 1,
+			grantedBy = excluded.grantedBy,
+			dateGranted = current_timestamp`,
+		weekID.String(), user, grantedBy)
+
+	return errors.Wrap(err, "")
+}
+
+func (context *ExceptionRepository) Exceptions(ctx context.Context, weekID general.WeekID, user string) (int, error) {
+	var remaining int
+	err := context.session.QueryRowContext(ctx, `
+		SELECT remaining
+		FROM suggestion_exceptions
+		WHERE weekID = ? AND name = ?`,
+		weekID.String(), user).Scan(&remaining)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	return remaining, errors.Wrap(err, "")
+}
+
+func (context *ExceptionRepository) UseException(ctx context.Context, weekID general.WeekID, user string) (bool, error) {
+	result, err := context.session.ExecContext(ctx, `
+		UPDATE suggestion_exceptions
+		SET remaining = remaining - 1
+		WHERE weekID = ? AND name = ? AND remaining > 0`,
+		weekID.String(), user)
+	if err != nil {
+		return false, errors.Wrap(err, "")
+	}
+
+	used, err := result.RowsAffected()
+	return used > 0, errors.Wrap(err, "")
+}
@@ -1,129 +1,236 @@
 package suggestion
 
 import (
+	"context"
 	"database/sql"
-	"log"
 
 	"github.com/pkg/errors"
 
 	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
 )
 
+// Repository is the SQL Store, for SQLite and PostgreSQL.
 type Repository struct {
-	session *sql.DB
+	session *storage.DB
 }
 
-func NewRepository(session *sql.DB) *Repository {
+func NewRepository(session *storage.DB) *Repository {
 	return &Repository{
 		session: session,
 	}
 }
 
-func (context *Repository) Save(s Suggestion) error {
-	stmt, err := context.session.Prepare(
+// Save numbers the suggestion after the week's last one. Numbers of removed
+// suggestions aren't handed out again.
+func (context *Repository) Save(ctx context.Context, s Suggestion) error {
+	tx, err := context.session.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	// The upsert locks the week's counter until the suggestion is saved
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO suggestion_numbers (weekID, lastNumber)
+		VALUES (?, 1)
+		ON CONFLICT(weekID) DO UPDATE SET lastNumber = suggestion_numbers.lastNumber + 1`,
+		s.WeekID.String())
+	if err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "")
+	}
+
+	var number int
+	err = tx.QueryRowContext(ctx, "SELECT lastNumber FROM suggestion_numbers WHERE weekID = ?", s.WeekID.String()).Scan(&number)
+	if err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "")
+	}
+
+	_, err = tx.ExecContext(ctx,
 		`INSERT INTO suggestions (
 			uuid,
 			weekID,
+			number,
 			author,
 			movie,
-			movieHash
+			movieHash,
+			movieID,
+			pitch,
+			link
 		) VALUES (
+			?,
+			?,
+			?,
+			?,
 			?,
 			?,
 			?,
 			?,
 			?
-		)`)
-
+		)`,
+		s.ID.String(), s.WeekID.String(), number, s.Author, s.Movie.String(), s.Movie.Encode(), nullString(s.MovieID), s.Pitch, s.Link)
 	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, storage.ErrUniqueViolation) {
+			return ErrDuplicateMovie
+		}
+
 		return errors.Wrap(err, "")
 	}
 
-	_, err = stmt.Exec(s.ID.String(), s.WeekID.String(), s.Author,
-		s.Movie.String(), s.Movie.Encode())
-
-	return errors.Wrap(err, "")
+	return errors.Wrap(tx.Commit(), "")
 }
 
-func (context *Repository) AllSuggestions(weekID general.WeekID, callback func(key []byte, suggestion *Suggestion) error) {
-	stmt, err := context.session.Prepare("SELECT id, uuid, author, movie FROM suggestions WHERE weekID = ? ORDER BY id ASC")
+func (context *Repository) AllSuggestions(ctx context.Context, weekID general.WeekID) ([]Suggestion, error) {
+	rows, err := context.session.QueryContext(ctx, "SELECT number, uuid, author, movie, movieID, pitch, link FROM suggestions WHERE weekID = ? ORDER BY number ASC", weekID.String())
 	if err != nil {
-		log.Fatalf("[error] %+v", errors.Wrap(err, ""))
-		return
+		return nil, errors.Wrap(err, "")
 	}
+	defer rows.Close()
 
-	rows, err := stmt.Query(weekID.String())
-	if err != nil {
-		log.Fatalf("[error]  %+v", errors.Wrap(err, ""))
-		return
+	var suggestions []Suggestion
+	for rows.Next() {
+		var number int
+		var suggestionID string
+		var author string
+		var movie string
+		var movieID sql.NullString
+		var pitch string
+		var link string
+
+		if err := rows.Scan(&number, &suggestionID, &author, &movie, &movieID, &pitch, &link); err != nil {
+			return nil, errors.Wrap(err, "")
+		}
+
+		suggestions = append(suggestions, Suggestion{
+			ID:      ID(suggestionID),
+			WeekID:  weekID,
+			Author:  author,
+			Movie:   general.MovieFromString(movie),
+			Order:   OrderedID(number),
+			MovieID: movieID.String,
+			Pitch:   pitch,
+			Link:    link,
+		})
 	}
 
-	var id int
+	return suggestions, errors.Wrap(rows.Err(), "")
+}
+
+// GetSuggestionByOrder returns the week's suggestion with the given number.
+func (context *Repository) GetSuggestionByOrder(ctx context.Context, weekID general.WeekID, orderID OrderedID) (*Suggestion, error) {
+	row := context.session.QueryRowContext(ctx, "SELECT uuid, author, movie, movieID, pitch, link FROM suggestions WHERE weekID = ? AND number = ?", weekID.String(), orderID)
+
 	var suggestionID string
 	var author string
 	var movie string
+	var movieID sql.NullString
+	var pitch string
+	var link string
 
-	for rows.Next() {
-		err = rows.Scan(&id, &suggestionID, &author, &movie)
-		if err != nil {
-			return
-		}
-
-		err = callback(
-			[]byte(suggestionID),
-			&Suggestion{
-				ID:     ID(suggestionID),
-				WeekID: weekID,
-				Author: author,
-				Movie:  general.MovieFromString(movie),
-				Order:  OrderedID(id),
-			})
+	err := row.Scan(&suggestionID, &author, &movie, &movieID, &pitch, &link)
+	if err == sql.ErrNoRows {
+		return nil, ErrUnknownSuggestion
+	}
 
-		if err != nil {
-			log.Fatalf("[error] %+v", errors.Wrap(err, ""))
-			return
-		}
+	if err != nil {
+		return nil, errors.Wrap(err, "")
 	}
+
+	return &Suggestion{
+		ID:      ID(suggestionID),
+		WeekID:  weekID,
+		Author:  author,
+		Movie:   general.MovieFromString(movie),
+		Order:   orderID,
+		MovieID: movieID.String,
+		Pitch:   pitch,
+		Link:    link,
+	}, nil
 }
 
-// GetSuggestionByOrder Given the order id, return the suggestion at that position
-func (context *Repository) GetSuggestionByOrder(orderID OrderedID) *Suggestion {
-	stmt, err := context.session.Prepare("SELECT id, uuid, weekID, author, movie FROM suggestions WHERE id = ?")
+func (context *Repository) Remove(ctx context.Context, s Suggestion) error {
+	_, err := context.session.ExecContext(ctx, "DELETE FROM suggestions WHERE uuid = ?", s.ID.String())
+	return errors.Wrap(err, "")
+}
+
+// Rename records the previous title and updates the suggestion in one
+// transaction, so a refused title leaves no history behind.
+func (context *Repository) Rename(ctx context.Context, s Suggestion, by string) error {
+	tx, err := context.session.BeginTx(ctx, nil)
 	if err != nil {
-		log.Fatalf("[error] %+v", errors.Wrap(err, ""))
-		return nil
+		return errors.Wrap(err, "")
 	}
 
-	row := stmt.QueryRow(orderID)
-
-	var id int
-	var suggestionID string
-	var weekID string
-	var author string
-	var movie string
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO suggestion_renames (suggestionID, movie, movieID, renamedBy)
+		SELECT id, movie, movieID, ?
+		FROM suggestions
+		WHERE uuid = ?`,
+		by, s.ID.String())
+	if err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "")
+	}
 
-	err = row.Scan(&id, &suggestionID, &weekID, &author, &movie)
+	result, err := tx.ExecContext(ctx, `
+		UPDATE suggestions
+		SET movie = ?, movieHash = ?, movieID = ?
+		WHERE uuid = ?`,
+		s.Movie.String(), s.Movie.Encode(), nullString(s.MovieID), s.ID.String())
 	if err != nil {
-		return nil
+		tx.Rollback()
+		if errors.Is(err, storage.ErrUniqueViolation) {
+			return ErrDuplicateMovie
+		}
+
+		return errors.Wrap(err, "")
 	}
 
-	parsedWeekID, _ := general.WeekIDFromString(weekID)
+	if renamed, err := result.RowsAffected(); err != nil || renamed == 0 {
+		tx.Rollback()
+		if err != nil {
+			return errors.Wrap(err, "")
+		}
 
-	return &Suggestion{
-		ID:     ID(suggestionID),
-		WeekID: *parsedWeekID,
-		Author: author,
-		Movie:  general.MovieFromString(movie),
-		Order:  OrderedID(id),
+		return ErrUnknownSuggestion
 	}
+
+	return errors.Wrap(tx.Commit(), "")
 }
 
-func (context *Repository) Remove(s Suggestion) error {
-	stmt, err := context.session.Prepare("DELETE FROM suggestions WHERE uuid = ?")
+func (context *Repository) Renames(ctx context.Context, s Suggestion) ([]Rename, error) {
+	rows, err := context.session.QueryContext(ctx, `
+		SELECT r.movie, r.movieID, r.renamedBy, r.dateRenamed
+		FROM suggestion_renames r
+		INNER JOIN suggestions s
+			ON s.id = r.suggestionID
+		WHERE s.uuid = ?
+		ORDER BY r.id`, s.ID.String())
 	if err != nil {
-		return errors.Wrap(err, "")
+		return nil, errors.Wrap(err, "")
 	}
+	defer rows.Close()
 
-	_, err = stmt.Exec(s.ID.String())
-	return errors.Wrap(err, "")
+	var renames []Rename
+	for rows.Next() {
+		var rename Rename
+		var movie string
+		var movieID sql.NullString
+
+		if err := rows.Scan(&movie, &movieID, &rename.By, &rename.Renamed); err != nil {
+			return nil, errors.Wrap(err, "")
+		}
+
+		rename.Movie = general.MovieFromString(movie)
+		rename.MovieID = movieID.String
+		renames = append(renames, rename)
+	}
+
+	return renames, errors.Wrap(rows.Err(), "")
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
 }
@@ -0,0 +1,199 @@
+package suggestion_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/dbtest"
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+	"github.com/fredlawl/200-colony-movie-night-bot/suggestion"
+)
+
+var week = general.WeekID{IsoYear: 2021, IsoWeek: 14}
+
+// save adds the movie to the week as the author's suggestion.
+func save(t *testing.T, repository suggestion.Store, author string, movie string) suggestion.Suggestion {
+	s, err := suggestion.NewSuggestion(week, author, general.MovieFromString(movie))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repository.Save(context.Background(), *s); err != nil {
+		t.Fatal(err)
+	}
+
+	return *s
+}
+
+func TestGivenSavedSuggestionsAllSuggestionsNumbersThemInOrder(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, session *storage.DB) {
+		repository := suggestion.NewRepository(session)
+		ctx := context.Background()
+
+		shrek, err := suggestion.NewSuggestion(week, "liam", general.MovieFromString("Shrek"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		shrek.MovieID = "tt0126029"
+		shrek.Pitch = "An ogre rescues a princess."
+		shrek.Link = "https://example.com/shrek"
+
+		if err := repository.Save(ctx, *shrek); err != nil {
+			t.Fatal(err)
+		}
+		save(t, repository, "noah", "Cars")
+
+		all, err := repository.AllSuggestions(ctx, week)
+		if err != nil || len(all) != 2 {
+			t.Fatalf("expected 2 suggestions, got %v %+v", all, err)
+		}
+
+		first := all[0]
+		if first.Order != 1 || first.Movie != "Shrek" || first.Author != "liam" || first.MovieID != "tt0126029" || first.Pitch != shrek.Pitch || first.Link != shrek.Link {
+			t.Fail()
+		}
+
+		if all[1].Order != 2 || all[1].Movie != "Cars" || all[1].MovieID != "" {
+			t.Fail()
+		}
+	})
+}
+
+func TestGivenTheSameMovieTwiceInAWeekSaveRefusesIt(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, session *storage.DB) {
+		repository := suggestion.NewRepository(session)
+		save(t, repository, "liam", "Shrek")
+
+		again, err := suggestion.NewSuggestion(week, "noah", general.MovieFromString("shrek"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := repository.Save(context.Background(), *again); !errors.Is(err, suggestion.ErrDuplicateMovie) {
+			t.Fail()
+		}
+	})
+}
+
+func TestGivenANewWeekSuggestionsAreNumberedFromOneAgain(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, session *storage.DB) {
+		repository := suggestion.NewRepository(session)
+		ctx := context.Background()
+		save(t, repository, "liam", "Shrek")
+
+		next := general.WeekIDFromTime(time.Date(2021, 4, 12, 12, 0, 0, 0, time.UTC))
+		cars, err := suggestion.NewSuggestion(next, "noah", general.MovieFromString("Cars"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := repository.Save(ctx, *cars); err != nil {
+			t.Fatal(err)
+		}
+
+		found, err := repository.GetSuggestionByOrder(ctx, next, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, unknown := repository.GetSuggestionByOrder(ctx, next, 2)
+
+		if found.Movie != "Cars" || !errors.Is(unknown, suggestion.ErrUnknownSuggestion) {
+			t.Fail()
+		}
+	})
+}
+
+func TestGivenARenameTheSuggestionKeepsItsNumberAndPreviousTitle(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, session *storage.DB) {
+		repository := suggestion.NewRepository(session)
+		ctx := context.Background()
+		shrke := save(t, repository, "liam", "Shrke")
+		cars := save(t, repository, "liam", "Cars")
+
+		shrke.Movie = general.MovieFromString("Shrek")
+		if err := repository.Rename(ctx, shrke, "liam"); err != nil {
+			t.Fatal(err)
+		}
+
+		// The week already has Shrek, the refused title leaves no history
+		cars.Movie = general.MovieFromString("shrek")
+		refused := repository.Rename(ctx, cars, "noah")
+
+		renamed, err := repository.GetSuggestionByOrder(ctx, week, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		renames, err := repository.Renames(ctx, shrke)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		carsRenames, err := repository.Renames(ctx, cars)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !errors.Is(refused, suggestion.ErrDuplicateMovie) || renamed.Movie != "Shrek" ||
+			len(renames) != 1 || renames[0].Movie != "Shrke" || renames[0].By != "liam" || len(carsRenames) != 0 {
+			t.Fail()
+		}
+	})
+}
+
+func TestGivenARemovedSuggestionItsSecondsAreRemoved(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, session *storage.DB) {
+		repository := suggestion.NewRepository(session)
+		seconds := suggestion.NewSecondRepository(session)
+		ctx := context.Background()
+		shrek := save(t, repository, "liam", "Shrek")
+		save(t, repository, "liam", "Cars")
+
+		if err := seconds.SaveSecond(ctx, suggestion.Second{WeekID: week, Order: 1, User: "noah"}); err != nil {
+			t.Fatal(err)
+		}
+
+		again := seconds.SaveSecond(ctx, suggestion.Second{WeekID: week, Order: 1, User: "noah"})
+		if err := repository.Remove(ctx, shrek); err != nil {
+			t.Fatal(err)
+		}
+
+		unknown := seconds.SaveSecond(ctx, suggestion.Second{WeekID: week, Order: 1, User: "noah"})
+		left, err := seconds.Seconds(ctx, week)
+
+		if !errors.Is(again, suggestion.ErrAlreadySeconded) || !errors.Is(unknown, suggestion.ErrUnknownSuggestion) || err != nil || len(left) != 0 {
+			t.Fail()
+		}
+	})
+}
+
+func TestGivenAGrantedExceptionItIsSpentOnce(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, session *storage.DB) {
+		exceptions := suggestion.NewExceptionRepository(session)
+		ctx := context.Background()
+
+		if err := exceptions.GrantException(ctx, week, "liam", "olivia"); err != nil {
+			t.Fatal(err)
+		}
+
+		first, err := exceptions.UseException(ctx, week, "liam")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		second, err := exceptions.UseException(ctx, week, "liam")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		remaining, err := exceptions.Exceptions(ctx, week, "liam")
+
+		if !first || second || err != nil || remaining != 0 {
+			t.Fail()
+		}
+	})
+}
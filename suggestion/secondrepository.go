@@ -0,0 +1,74 @@
+package suggestion
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+)
+
+// SecondRepository is the SQL SecondStore, for SQLite and PostgreSQL.
+type SecondRepository struct {
+	session *storage.DB
+}
+
+func NewSecondRepository(session *storage.DB) *SecondRepository {
+	return &SecondRepository{
+		session: session,
+	}
+}
+
+func (context *SecondRepository) SaveSecond(ctx context.Context, second Second) error {
+	result, err := context.session.ExecContext(ctx, `
+		INSERT INTO suggestion_seconds (suggestionID, weekID, name)
+		SELECT id, weekID, ?
+		FROM suggestions
+		WHERE weekID = ? AND number = ?`,
+		second.User, second.WeekID.String(), second.Order)
+	if errors.Is(err, storage.ErrUniqueViolation) {
+		return ErrAlreadySeconded
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	saved, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	if saved == 0 {
+		return ErrUnknownSuggestion
+	}
+
+	return nil
+}
+
+func (context *SecondRepository) Seconds(ctx context.Context, weekID general.WeekID) ([]Second, error) {
+	rows, err := context.session.QueryContext(ctx, `
+		SELECT s.number, ss.name
+		FROM suggestion_seconds ss
+		INNER JOIN suggestions s
+			ON s.id = ss.suggestionID
+		WHERE ss.weekID = ?
+		ORDER BY ss.id`, weekID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	defer rows.Close()
+
+	var seconds []Second
+	for rows.Next() {
+		second := Second{WeekID: weekID}
+		if err := rows.Scan(&second.Order, &second.User); err != nil {
+			return nil, errors.Wrap(err, "")
+		}
+
+		seconds = append(seconds, second)
+	}
+
+	return seconds, errors.Wrap(rows.Err(), "")
+}
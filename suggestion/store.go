@@ -0,0 +1,63 @@
+package suggestion
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+)
+
+var (
+	// ErrDuplicateMovie is returned when the movie was already suggested
+	// this week.
+	ErrDuplicateMovie = errors.New("movie was already suggested")
+	// ErrUnknownSuggestion is returned when a suggestion doesn't exist.
+	ErrUnknownSuggestion = errors.New("suggestion does not exist")
+	// ErrAlreadySeconded is returned when the user already seconded the
+	// suggestion.
+	ErrAlreadySeconded = errors.New("suggestion was already seconded")
+)
+
+// Store keeps the suggestions of each week.
+type Store interface {
+	// Save numbers the suggestion within its week, starting at 1. It
+	// returns ErrDuplicateMovie when the movie was already suggested the
+	// same week.
+	Save(ctx context.Context, s Suggestion) error
+	// AllSuggestions lists the week's suggestions in the order they were
+	// made.
+	AllSuggestions(ctx context.Context, weekID general.WeekID) ([]Suggestion, error)
+	// GetSuggestionByOrder returns ErrUnknownSuggestion when no suggestion
+	// of the week has the number.
+	GetSuggestionByOrder(ctx context.Context, weekID general.WeekID, orderID OrderedID) (*Suggestion, error)
+	Remove(ctx context.Context, s Suggestion) error
+	// Rename changes the movie of the saved suggestion to s.Movie and
+	// s.MovieID, keeping the previous title. It returns ErrDuplicateMovie
+	// when the movie was already suggested the same week.
+	Rename(ctx context.Context, s Suggestion, by string) error
+	// Renames lists the previous titles of the suggestion, oldest first.
+	Renames(ctx context.Context, s Suggestion) ([]Rename, error)
+}
+
+// ExceptionStore keeps the exceptions moderators grant to the weekly
+// suggestion limits. Each exception lets a user add one suggestion past the
+// limits.
+type ExceptionStore interface {
+	GrantException(ctx context.Context, weekID general.WeekID, user string, grantedBy string) error
+	// Exceptions counts the exceptions the user has left in the week.
+	Exceptions(ctx context.Context, weekID general.WeekID, user string) (int, error)
+	// UseException spends one of the user's exceptions, returning false
+	// when they have none left.
+	UseException(ctx context.Context, weekID general.WeekID, user string) (bool, error)
+}
+
+// SecondStore keeps who seconded the suggestions of each week. Seconds are
+// removed with their suggestion.
+type SecondStore interface {
+	// SaveSecond returns ErrUnknownSuggestion when the week has no
+	// suggestion with the number, and ErrAlreadySeconded when the user
+	// seconded it before.
+	SaveSecond(ctx context.Context, second Second) error
+	// Seconds lists the week's seconds in the order they were made.
+	Seconds(ctx context.Context, weekID general.WeekID) ([]Second, error)
+}
@@ -2,12 +2,19 @@ package suggestion
 
 import (
 	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/history"
 	"github.com/google/uuid"
 )
 
 type ID string
+
+// OrderedID is the number of a suggestion within its week, starting at 1. It
+// is what users vote and remove suggestions by.
 type OrderedID uint64
 
 func (SuggestionId ID) String() string {
@@ -20,11 +27,31 @@ type Suggestion struct {
 	Author string
 	Movie  general.Movie
 	Order  OrderedID
+	// MovieID is the catalog record the movie was resolved to, empty when
+	// the title wasn't found.
+	MovieID string
+	// Pitch is the author's case for watching the movie.
+	Pitch string
+	Link  string
 }
 
-func NewSuggestion(weekID general.WeekID, author string, movie general.Movie) (*Suggestion, error) {
+// ErrUntitledMovie is returned for a title without any letters or digits,
+// which can't be told apart from other titles.
+var ErrUntitledMovie = errors.New("movie could not be encoded")
+
+// CheckTitle fails with ErrUntitledMovie when the movie can't be suggested
+// under its title.
+func CheckTitle(movie general.Movie) error {
 	if len(movie.Encode()) == 0 {
-		return nil, errors.New("movie could not be encoded")
+		return ErrUntitledMovie
+	}
+
+	return nil
+}
+
+func NewSuggestion(weekID general.WeekID, author string, movie general.Movie) (*Suggestion, error) {
+	if err := CheckTitle(movie); err != nil {
+		return nil, err
 	}
 
 	suggestionID := ID(uuid.New().String())
@@ -36,3 +63,144 @@ func NewSuggestion(weekID general.WeekID, author string, movie general.Movie) (*
 		Order:  1,
 	}, nil
 }
+
+// Carry copies a suggestion into another week for the same author.
+func Carry(s Suggestion, weekID general.WeekID) (*Suggestion, error) {
+	carried, err := NewSuggestion(weekID, s.Author, s.Movie)
+	if err != nil {
+		return nil, err
+	}
+
+	carried.MovieID = s.MovieID
+	carried.Pitch = s.Pitch
+	carried.Link = s.Link
+
+	return carried, nil
+}
+
+// Excerpt shortens the pitch to its first line, cut to at most length
+// characters.
+func (s Suggestion) Excerpt(length int) string {
+	excerpt := strings.TrimSpace(s.Pitch)
+	if newline := strings.IndexByte(excerpt, '\n'); newline >= 0 {
+		excerpt = strings.TrimSpace(excerpt[:newline]) + "..."
+	}
+
+	if utf8.RuneCountInString(excerpt) <= length {
+		return excerpt
+	}
+
+	runes := []rune(excerpt)
+	return strings.TrimSpace(string(runes[:length-3])) + "..."
+}
+
+// Limit is a weekly limit adding a suggestion can go past.
+type Limit int
+
+const (
+	WithinLimits Limit = iota
+	// SuggestionLimit is how many movies each user may suggest a week.
+	SuggestionLimit
+	// BallotLimit is how many suggestions the ballot may hold.
+	BallotLimit
+)
+
+// CheckLimits finds the limit the author adding one more of the week's
+// suggestions would go past. Whether a moderator's exception lets it through
+// is up to the caller.
+func CheckLimits(cfg general.AppConfig, suggestions []Suggestion, author string) Limit {
+	own := 0
+	for _, s := range suggestions {
+		if s.Author == author {
+			own++
+		}
+	}
+
+	if cfg.SuggestionLimit > 0 && own >= cfg.SuggestionLimit {
+		return SuggestionLimit
+	}
+
+	if cfg.BallotLimit > 0 && len(suggestions) >= cfg.BallotLimit {
+		return BallotLimit
+	}
+
+	return WithinLimits
+}
+
+// InCooldown finds the last time the suggestion's movie was watched, nil
+// when it never was, and whether that was too recently to suggest it again.
+func InCooldown(cfg general.AppConfig, now time.Time, watched []history.Entry, s Suggestion) (*history.Entry, bool) {
+	last := history.LastWatched(watched, s.Movie, s.MovieID)
+	if last == nil {
+		return nil, false
+	}
+
+	return last, cfg.RewatchCooldown > 0 && now.Before(last.Available(cfg.RewatchCooldown))
+}
+
+// Eligibility is whether a suggestion may be added to a week.
+type Eligibility struct {
+	// Watched is the last time the movie was watched, nil when it never was.
+	Watched *history.Entry
+	// Cooldown is set when the movie was watched too recently to be
+	// suggested again.
+	Cooldown bool
+	Limit    Limit
+}
+
+// Eligible checks a suggestion carried into the week against the rewatch
+// cooldown and the weekly limits of its author, the same way whether a user
+// carries it or the runners-up are carried over.
+func Eligible(cfg general.AppConfig, now time.Time, suggestions []Suggestion, watched []history.Entry, s Suggestion) Eligibility {
+	last, cooldown := InCooldown(cfg, now, watched, s)
+
+	return Eligibility{
+		Watched:  last,
+		Cooldown: cooldown,
+		Limit:    CheckLimits(cfg, suggestions, s.Author),
+	}
+}
+
+// Second is a user backing someone else's suggestion so it makes the ballot.
+type Second struct {
+	WeekID general.WeekID
+	Order  OrderedID
+	User   string
+}
+
+// CountSeconds counts the seconds of each suggestion.
+func CountSeconds(seconds []Second) map[OrderedID]int {
+	counts := make(map[OrderedID]int)
+	for _, second := range seconds {
+		counts[second.Order]++
+	}
+
+	return counts
+}
+
+// Nominated keeps the suggestions seconded at least threshold times, which
+// are the ones that make the ballot. A threshold of 0 keeps every suggestion.
+func Nominated(suggestions []Suggestion, seconds []Second, threshold int) []Suggestion {
+	if threshold <= 0 {
+		return suggestions
+	}
+
+	counts := CountSeconds(seconds)
+
+	var nominated []Suggestion
+	for _, s := range suggestions {
+		if counts[s.Order] >= threshold {
+			nominated = append(nominated, s)
+		}
+	}
+
+	return nominated
+}
+
+// Rename is a title a suggestion had before it was edited.
+type Rename struct {
+	Movie   general.Movie
+	MovieID string
+	By      string
+	Renamed time.Time
+}
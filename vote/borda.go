@@ -0,0 +1,36 @@
+package vote
+
+// Borda awards each candidate points for every ballot based on its rank.
+// With N candidates a first preference is worth N-1 points, a second N-2 and
+// so on. Candidates left off a ballot receive nothing from it.
+type Borda struct{}
+
+func (Borda) Tally(candidates []Candidate, ballots []Ballot, ties TieBreaker) Result {
+	result := Result{
+		Method:     "Borda count",
+		Unit:       "Points",
+		Candidates: candidates,
+	}
+
+	if len(candidates) == 0 || len(ballots) == 0 {
+		return result
+	}
+
+	round := newRound(1, candidates)
+
+	for _, b := range ballots {
+		rank := 0
+		for _, id := range b.Ranking {
+			if _, exists := round.Scores[id]; !exists {
+				continue
+			}
+
+			round.Scores[id] += len(candidates) - 1 - rank
+			rank++
+		}
+	}
+
+	result.decideHighest(round, ties)
+
+	return result
+}
@@ -0,0 +1,182 @@
+package vote
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/history"
+	"github.com/fredlawl/200-colony-movie-night-bot/suggestion"
+)
+
+// Rank orders the candidates by running the tally again without the previous
+// winner until no ballot prefers any of the rest. Candidates nobody voted for
+// follow in the order they were suggested.
+func Rank(tallier Tallier, candidates []Candidate, ballots []Ballot, ties TieBreaker) []Candidate {
+	remaining := append([]Candidate(nil), candidates...)
+	var ranked []Candidate
+
+	for len(remaining) > 0 {
+		result := tallier.Tally(remaining, withoutCandidatesMissing(ballots, remaining), ties)
+		if result.Winner == nil {
+			break
+		}
+
+		ranked = append(ranked, *result.Winner)
+
+		var rest []Candidate
+		for _, c := range remaining {
+			if c.ID != result.Winner.ID {
+				rest = append(rest, c)
+			}
+		}
+		remaining = rest
+	}
+
+	return append(ranked, remaining...)
+}
+
+// withoutCandidatesMissing drops preferences for candidates no longer
+// counted, and ballots left empty.
+func withoutCandidatesMissing(ballots []Ballot, candidates []Candidate) []Ballot {
+	counted := make(map[suggestion.OrderedID]bool, len(candidates))
+	for _, c := range candidates {
+		counted[c.ID] = true
+	}
+
+	var kept []Ballot
+	for _, b := range ballots {
+		var ranking []suggestion.OrderedID
+		for _, id := range b.Ranking {
+			if counted[id] {
+				ranking = append(ranking, id)
+			}
+		}
+
+		if len(ranking) > 0 {
+			kept = append(kept, Ballot{Author: b.Author, Ranking: ranking})
+		}
+	}
+
+	return kept
+}
+
+// CarryOver copies the best ranked suggestions of a week's ballot into the
+// week of the settings, crediting their authors. The week's winner, when
+// anyone voted, stays behind. A negative limit carries every other
+// suggestion. Suggestions that aren't Eligible, watched within the rewatch
+// cooldown or past their author's weekly limit, are skipped for the next
+// best, as are movies already suggested in the new week. Carrying stops once
+// the ballot is full. Exceptions are only spent on suggestions users add
+// themselves.
+func CarryOver(ctx context.Context, suggestions suggestion.Store, votes Store, seconds suggestion.SecondStore, watched history.Store, settings *general.AppSettings, from general.WeekID, limit int) ([]suggestion.Suggestion, error) {
+	cfg := settings.Config
+	to := settings.WeekID
+
+	if limit == 0 {
+		return nil, nil
+	}
+
+	tallier, err := NewTallier(cfg.TallyMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	ties, err := NewTieBreaker(cfg.TieBreak, from)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := votes.Candidates(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err = onBallot(ctx, seconds, from, cfg.NominationThreshold, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	ballots, err := votes.Ballots(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := Rank(tallier, candidates, ballots, ties)
+	if len(ballots) > 0 && len(ranked) > 0 {
+		ranked = ranked[1:]
+	}
+
+	current, err := suggestions.AllSuggestions(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := watched.Watched(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var carried []suggestion.Suggestion
+	for _, c := range ranked {
+		if limit > 0 && len(carried) == limit {
+			break
+		}
+
+		original, err := suggestions.GetSuggestionByOrder(ctx, from, c.ID)
+		if err != nil {
+			return carried, err
+		}
+
+		copied, err := suggestion.Carry(*original, to)
+		if err != nil {
+			return carried, err
+		}
+
+		eligibility := suggestion.Eligible(cfg, settings.Now, current, entries, *copied)
+		if eligibility.Limit == suggestion.BallotLimit {
+			break
+		}
+
+		if eligibility.Cooldown || eligibility.Limit != suggestion.WithinLimits {
+			continue
+		}
+
+		saveErr := suggestions.Save(ctx, *copied)
+		if errors.Is(saveErr, suggestion.ErrDuplicateMovie) {
+			continue
+		}
+
+		if saveErr != nil {
+			return carried, saveErr
+		}
+
+		current = append(current, *copied)
+		carried = append(carried, *copied)
+	}
+
+	return carried, nil
+}
+
+// onBallot keeps the candidates seconded enough to make the week's ballot.
+func onBallot(ctx context.Context, seconds suggestion.SecondStore, weekID general.WeekID, threshold int, candidates []Candidate) ([]Candidate, error) {
+	if threshold <= 0 {
+		return candidates, nil
+	}
+
+	weekSeconds, err := seconds.Seconds(ctx, weekID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := suggestion.CountSeconds(weekSeconds)
+
+	var nominated []Candidate
+	for _, c := range candidates {
+		if counts[c.ID] >= threshold {
+			nominated = append(nominated, c)
+		}
+	}
+
+	return nominated, nil
+}
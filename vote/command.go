@@ -1,16 +1,16 @@
 package vote
 
 import (
-	"database/sql"
 	"errors"
 	"fmt"
 	"log"
 	"strconv"
+	"strings"
 
+	"github.com/fredlawl/200-colony-movie-night-bot/auth"
 	"github.com/fredlawl/200-colony-movie-night-bot/general"
 	"github.com/fredlawl/200-colony-movie-night-bot/suggestion"
 	"github.com/google/uuid"
-	"github.com/mattn/go-sqlite3"
 	"github.com/urfave/cli/v2"
 )
 
@@ -19,6 +19,33 @@ func Command() *cli.Command {
     mov votes cast [Suggestion ID 1], [Suggestion ID 2], ... [Suggestion ID N]
 
 	To recast votes, this command must be written again. All previous votes will be nullified and replaced with this new order.
+
+	When a nomination threshold is configured only suggestions seconded that many times are on the ballot.
+
+Show or withdraw the votes you cast this week:
+    mov votes mine
+    mov votes withdraw
+
+Show the results once voting has ended:
+    mov votes results [--week YYYYWW] [--method irv|borda|schulze|plurality] [--tie-break earliest|fewest-wins|random]
+
+	Ballots are counted with the configured method, instant-runoff by default:
+	  irv        the movie with the fewest first preferences is eliminated each round until one movie has a majority
+	  borda      each rank is worth points, the movie with the most points wins
+	  schulze    the movie that beats the others head to head wins
+	  plurality  the movie with the most first preferences wins
+
+	Ties are broken with the configured rule, earliest suggestion by default:
+	  earliest     the movie suggested first
+	  fewest-wins  the movie whose author has won the fewest past movie nights
+	  random       a random draw seeded with the week so anyone can reproduce it
+
+	The flags only change how the results are shown. The winner is counted with the configured method and rule and recorded, once, when movie night starts.
+
+Record the winner of a week the bot wasn't running for:
+    mov votes record [--week YYYYWW]
+
+	Only admins may record winners, and only weeks without one.
 `
 
 	return &cli.Command{
@@ -28,10 +55,61 @@ func Command() *cli.Command {
 		Description: description,
 		Subcommands: []*cli.Command{
 			{
-				Name:    "cast",
-				Aliases: []string{"c"},
-				Usage:   "Casts votes for for movies",
-				Action:  castVotesAction,
+				Name:      "cast",
+				Aliases:   []string{"c"},
+				Usage:     "Casts votes for for movies",
+				ArgsUsage: "<ids>...",
+				// Ballots are secret, the bot answers only the voter, see
+				// bot.PrivateCategory
+				Category: "private",
+				Action:   castVotesAction,
+			},
+			{
+				Name:     "mine",
+				Usage:    "Shows the votes you cast this week",
+				Category: "private",
+				Action:   myVotesAction,
+			},
+			{
+				Name:     "withdraw",
+				Usage:    "Withdraws the votes you cast this week",
+				Category: "private",
+				Action:   withdrawVotesAction,
+			},
+			{
+				Name:    "results",
+				Aliases: []string{"r"},
+				Usage:   "Tallies the votes and shows the winner",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "week",
+						Aliases: []string{"w"},
+						Usage:   "week to tally formatted as YYYYWW, defaults to this week",
+					},
+					&cli.StringFlag{
+						Name:    "method",
+						Aliases: []string{"m"},
+						Usage:   "counting method, defaults to the configured method",
+					},
+					&cli.StringFlag{
+						Name:    "tie-break",
+						Aliases: []string{"t"},
+						Usage:   "tie-break rule, defaults to the configured rule",
+					},
+				},
+				Action: resultsAction,
+			},
+			{
+				Name:  "record",
+				Usage: "Records the winner of movie night, admins only",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "week",
+						Aliases: []string{"w"},
+						Usage:   "week to record formatted as YYYYWW, defaults to this week",
+					},
+				},
+				Action: recordWinnerAction,
 			},
 		},
 	}
@@ -39,23 +117,37 @@ func Command() *cli.Command {
 
 func castVotesAction(c *cli.Context) error {
 	settings := c.App.Metadata["settings"].(*general.AppSettings)
-	dbSession := c.App.Metadata["dbSession"].(*sql.DB)
+	voteRepository := c.App.Metadata["votes"].(Store)
 	author := c.String("user")
 	week := settings.WeekID
 
-	if settings.CurPeriod.Name != general.Voting && !c.Bool("bypass") {
-		_, writeErr := c.App.Writer.Write([]byte("Sorry, unable to cast votes. The vote period has already ended.\n"))
-		return writeErr
+	if closed, err := votingClosed(c, settings, "cast"); closed || err != nil {
+		return err
 	}
 
-	voteRepository := NewRepository(dbSession)
+	numSuggestions, err := voteRepository.SuggestionCnt(c.Context, week)
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load suggestions.\n"))
+		return err
+	}
 
-	numSuggestions := voteRepository.SuggestionCnt(week)
 	if numSuggestions == 0 {
 		_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("There are no suggestions this week! Add some :D\n")))
 		return writeErr
 	}
 
+	threshold := settings.Config.NominationThreshold
+	var secondCounts map[suggestion.OrderedID]int
+	if threshold > 0 {
+		seconds, err := c.App.Metadata["seconds"].(suggestion.SecondStore).Seconds(c.Context, week)
+		if err != nil {
+			c.App.Writer.Write([]byte("Unable to load seconds.\n"))
+			return err
+		}
+
+		secondCounts = suggestion.CountSeconds(seconds)
+	}
+
 	uniqueVotes := make(map[suggestion.OrderedID]struct{})
 	var emptyMember struct{}
 	var votes []Vote
@@ -68,6 +160,11 @@ func castVotesAction(c *cli.Context) error {
 		}
 
 		id := suggestion.OrderedID(suggestionOrderID)
+		if threshold > 0 && secondCounts[id] < threshold {
+			_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("Suggestion %d is not on the ballot.\n", id)))
+			return writeErr
+		}
+
 		_, exists := uniqueVotes[id]
 		if exists {
 			continue
@@ -84,7 +181,7 @@ func castVotesAction(c *cli.Context) error {
 		uniqueVotes[id] = emptyMember
 	}
 
-	saveResults, err := voteRepository.BulkSaveVotes(author, week, votes)
+	saveResults, err := voteRepository.BulkSaveVotes(c.Context, author, week, votes)
 	if err != nil {
 		c.App.Writer.Write([]byte("Unable to save votes. Something went wrong with the transaction.\n"))
 		return err
@@ -98,7 +195,7 @@ func castVotesAction(c *cli.Context) error {
 
 		log.Printf("[error] %v", sr.err)
 
-		if sr.err.(sqlite3.Error).Code == sqlite3.ErrConstraint {
+		if errors.Is(sr.err, suggestion.ErrUnknownSuggestion) {
 			c.App.Writer.Write([]byte(fmt.Sprintf("Suggestion %d does not exist.\n", sr.vote.SuggestionOrderedID)))
 		} else {
 			c.App.Writer.Write([]byte(fmt.Sprintf("Vote for suggestion %d resulted in an error.\n", sr.vote.SuggestionOrderedID)))
@@ -114,3 +211,273 @@ func castVotesAction(c *cli.Context) error {
 
 	return nil
 }
+
+func myVotesAction(c *cli.Context) error {
+	settings := c.App.Metadata["settings"].(*general.AppSettings)
+	voteRepository := c.App.Metadata["votes"].(Store)
+	author := c.String("user")
+	week := settings.WeekID
+
+	if closed, err := votingClosed(c, settings, "show"); closed || err != nil {
+		return err
+	}
+
+	mine, err := voteRepository.Ballot(c.Context, author, week)
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load votes.\n"))
+		return err
+	}
+
+	if mine == nil {
+		_, writeErr := c.App.Writer.Write([]byte("You haven't cast any votes this week.\n"))
+		return writeErr
+	}
+
+	candidates, err := voteRepository.Candidates(c.Context, week)
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load suggestions.\n"))
+		return err
+	}
+
+	movies := make(map[suggestion.OrderedID]string, len(candidates))
+	for _, candidate := range candidates {
+		movies[candidate.ID] = candidate.Movie.String()
+	}
+
+	var outputBuffer strings.Builder
+
+	outputBuffer.WriteString(fmt.Sprintf("Your votes for week %s, in order of preference:\n", week))
+	for i, id := range mine.Ranking {
+		outputBuffer.WriteString(fmt.Sprintf("%d. #%d %s\n", i+1, id, movies[id]))
+	}
+
+	_, writeErr := c.App.Writer.Write([]byte(outputBuffer.String()))
+	return writeErr
+}
+
+func withdrawVotesAction(c *cli.Context) error {
+	settings := c.App.Metadata["settings"].(*general.AppSettings)
+	voteRepository := c.App.Metadata["votes"].(Store)
+
+	if closed, err := votingClosed(c, settings, "withdraw"); closed || err != nil {
+		return err
+	}
+
+	withdrawn, err := voteRepository.WithdrawVotes(c.Context, c.String("user"), settings.WeekID)
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to withdraw votes.\n"))
+		return err
+	}
+
+	output := "Your votes were withdrawn.\n"
+	if withdrawn == 0 {
+		output = "You haven't cast any votes this week.\n"
+	}
+
+	_, writeErr := c.App.Writer.Write([]byte(output))
+	return writeErr
+}
+
+// votingClosed tells the user when they can't do what they asked because
+// the voting period isn't open.
+func votingClosed(c *cli.Context, settings *general.AppSettings, action string) (bool, error) {
+	ignorePeriods, authErr := auth.Allowed(c, auth.IgnorePeriods)
+	if authErr != nil {
+		return true, authErr
+	}
+
+	if settings.CurPeriod.Name == general.Voting || ignorePeriods {
+		return false, nil
+	}
+
+	_, writeErr := c.App.Writer.Write([]byte("Sorry, unable to " + action + " votes. " + settings.ClosedReason(general.Voting) + "\n"))
+	return true, writeErr
+}
+
+func resultsAction(c *cli.Context) error {
+	settings := c.App.Metadata["settings"].(*general.AppSettings)
+	voteRepository := c.App.Metadata["votes"].(Store)
+	week := settings.WeekID
+
+	if c.IsSet("week") {
+		parsedWeek, parseErr := general.WeekIDFromString(c.String("week"))
+		if parseErr != nil {
+			_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("\"%s\" is not a valid week. Use the format YYYYWW.\n", c.String("week"))))
+			return writeErr
+		}
+		week = *parsedWeek
+	}
+
+	votingOver := settings.CurPeriod.Name == general.MovieNight || settings.CurPeriod.Name == general.Sleep
+	ignorePeriods, authErr := auth.Allowed(c, auth.IgnorePeriods)
+	if authErr != nil {
+		return authErr
+	}
+
+	if week == settings.WeekID && !votingOver && !ignorePeriods {
+		_, writeErr := c.App.Writer.Write([]byte("Sorry, results are not available until the vote period has ended.\n"))
+		return writeErr
+	}
+
+	method := settings.Config.TallyMethod
+	if c.IsSet("method") {
+		method = c.String("method")
+	}
+
+	tallier, err := NewTallier(method)
+	if err != nil {
+		_, writeErr := c.App.Writer.Write([]byte(err.Error() + "\n"))
+		return writeErr
+	}
+
+	tieBreak := settings.Config.TieBreak
+	if c.IsSet("tie-break") {
+		tieBreak = c.String("tie-break")
+	}
+
+	tieBreaker, err := NewTieBreaker(tieBreak, week)
+	if err != nil {
+		_, writeErr := c.App.Writer.Write([]byte(err.Error() + "\n"))
+		return writeErr
+	}
+
+	candidates, err := voteRepository.Candidates(c.Context, week)
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load suggestions.\n"))
+		return err
+	}
+
+	candidates, err = onBallot(c.Context, c.App.Metadata["seconds"].(suggestion.SecondStore), week, settings.Config.NominationThreshold, candidates)
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load seconds.\n"))
+		return err
+	}
+
+	ballots, err := voteRepository.Ballots(c.Context, week)
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load votes.\n"))
+		return err
+	}
+
+	if len(ballots) == 0 {
+		_, writeErr := c.App.Writer.Write([]byte("No votes were cast.\n"))
+		return writeErr
+	}
+
+	result := tallier.Tally(candidates, ballots, tieBreaker)
+
+	_, writeErr := c.App.Writer.Write([]byte(formatResult(result)))
+	return writeErr
+}
+
+func recordWinnerAction(c *cli.Context) error {
+	if allowed, err := auth.Require(c, auth.OverridePeriods); !allowed {
+		return err
+	}
+
+	settings := c.App.Metadata["settings"].(*general.AppSettings)
+	week := settings.WeekID
+
+	if c.IsSet("week") {
+		parsedWeek, parseErr := general.WeekIDFromString(c.String("week"))
+		if parseErr != nil {
+			_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("\"%s\" is not a valid week. Use the format YYYYWW.\n", c.String("week"))))
+			return writeErr
+		}
+		week = *parsedWeek
+	}
+
+	votingOver := settings.CurPeriod.Name == general.MovieNight || settings.CurPeriod.Name == general.Sleep
+	if week == settings.WeekID && !votingOver {
+		_, writeErr := c.App.Writer.Write([]byte("Sorry, the winner can't be recorded until the vote period has ended.\n"))
+		return writeErr
+	}
+
+	weekSettings, err := general.WeekSettings(c.Context, settings.Config, week, c.App.Metadata["overrides"].(general.OverrideStore))
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to load the week's schedule.\n"))
+		return err
+	}
+
+	winner, err := RecordWinner(c.Context, c.App.Metadata["votes"].(Store), c.App.Metadata["seconds"].(suggestion.SecondStore), weekSettings)
+	if errors.Is(err, ErrWinnerRecorded) {
+		_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("The winner of week %s was already recorded.\n", week)))
+		return writeErr
+	}
+
+	if err != nil {
+		c.App.Writer.Write([]byte("Unable to record the winner.\n"))
+		return err
+	}
+
+	if winner == nil {
+		_, writeErr := c.App.Writer.Write([]byte("No votes were cast.\n"))
+		return writeErr
+	}
+
+	_, writeErr := c.App.Writer.Write([]byte(fmt.Sprintf("Recorded #%d %s as the winner of week %s.\n", winner.ID, winner.Movie.String(), week)))
+	return writeErr
+}
+
+func formatResult(result Result) string {
+	var outputBuffer strings.Builder
+
+	outputBuffer.WriteString(fmt.Sprintf("Counted with %s\n\n", result.Method))
+
+	for _, round := range result.Rounds {
+		outputBuffer.WriteString(fmt.Sprintf("Round %d\n", round.Number))
+		outputBuffer.WriteString(fmt.Sprintf("%-4s%-33.32s%s\n", "ID", "Movie", result.Unit))
+
+		for _, c := range result.Candidates {
+			score, inRound := round.Scores[c.ID]
+			if !inRound {
+				continue
+			}
+
+			outputBuffer.WriteString(fmt.Sprintf("%-4d%-33.32s%d\n", c.ID, c.Movie.String(), score))
+		}
+
+		if round.Exhausted > 0 {
+			outputBuffer.WriteString(fmt.Sprintf("Exhausted ballots: %d\n", round.Exhausted))
+		}
+
+		for _, tie := range result.Ties {
+			if tie.Round == round.Number && !tie.ForWinner {
+				outputBuffer.WriteString(formatTie(result, tie, "is eliminated"))
+			}
+		}
+
+		for _, id := range round.Eliminated {
+			outputBuffer.WriteString(fmt.Sprintf("Eliminated: #%d %s\n", id, result.Candidate(id).Movie.String()))
+		}
+
+		outputBuffer.WriteString("\n")
+	}
+
+	if result.Winner == nil {
+		outputBuffer.WriteString("No winner.\n")
+		return outputBuffer.String()
+	}
+
+	for _, tie := range result.Ties {
+		if tie.ForWinner {
+			outputBuffer.WriteString(formatTie(result, tie, "wins"))
+		}
+	}
+
+	outputBuffer.WriteString(fmt.Sprintf("Winner: #%d %s\n", result.Winner.ID, result.Winner.Movie.String()))
+
+	return outputBuffer.String()
+}
+
+func formatTie(result Result, tie Tie, outcome string) string {
+	tied := make([]string, len(tie.Tied))
+	for i, id := range tie.Tied {
+		tied[i] = fmt.Sprintf("#%d %s", id, result.Candidate(id).Movie.String())
+	}
+
+	chosen := result.Candidate(tie.Chosen)
+
+	return fmt.Sprintf("Tie between %s broken by %s: #%d %s %s\n",
+		strings.Join(tied, ", "), tie.Rule, chosen.ID, chosen.Movie.String(), outcome)
+}
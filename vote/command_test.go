@@ -0,0 +1,237 @@
+package vote_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/movtest"
+	"github.com/fredlawl/200-colony-movie-night-bot/suggestion"
+	"github.com/fredlawl/200-colony-movie-night-bot/vote"
+)
+
+// nextWeek is the settings of the week after the harness's, when its
+// runners-up are carried over.
+func nextWeek(t *testing.T, h *movtest.Harness, cfg general.AppConfig) *general.AppSettings {
+	settings, err := general.WeekSettings(context.Background(), cfg, general.WeekIDFromTime(h.Clock.Now().AddDate(0, 0, 7)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return settings
+}
+
+func TestGivenAWeekOfVotingTheResultsShowTheWinner(t *testing.T) {
+	h := movtest.New(t)
+	h.MustRun("liam", "suggestions add Shrek")
+	h.MustRun("noah", `suggestions add "Winnie the Pooh"`)
+
+	h.Weekday(time.Thursday)
+	h.MustRun("liam", "votes cast 1 2")
+	h.MustRun("noah", "votes cast 2 1")
+	h.MustRun("oliver", "votes cast 2")
+
+	h.Weekday(time.Friday)
+	output := h.MustRun("liam", "votes results")
+
+	if !strings.Contains(output, "Winner: #2 Winnie the Pooh") {
+		t.Fail()
+	}
+}
+
+func TestGivenTheSuggestingPeriodVotesAreClosed(t *testing.T) {
+	h := movtest.New(t)
+	h.MustRun("liam", "suggestions add Shrek")
+
+	output := h.MustRun("liam", "votes cast 1")
+
+	if !strings.Contains(output, "unable to cast votes") {
+		t.Fail()
+	}
+}
+
+func TestGivenARemovedSuggestionItsVotesAreNotCounted(t *testing.T) {
+	h := movtest.New(t)
+	h.Config.Admins = []string{"olivia"}
+	h.MustRun("liam", "suggestions add Shrek")
+	h.MustRun("noah", `suggestions add "Winnie the Pooh"`)
+
+	h.Weekday(time.Thursday)
+	h.MustRun("liam", "votes cast 1")
+	h.MustRun("noah", "votes cast 1 2")
+	h.MustRun("olivia", "suggestions remove 1")
+
+	h.Weekday(time.Friday)
+	output := h.MustRun("liam", "votes results")
+
+	if strings.Contains(output, "Shrek") || !strings.Contains(output, "Winner: #2 Winnie the Pooh") {
+		t.Fail()
+	}
+}
+
+func TestGivenAFinishedWeekTheRunnersUpAreCarriedOver(t *testing.T) {
+	h := movtest.New(t)
+	h.MustRun("liam", "suggestions add Shrek")
+	h.MustRun("noah", `suggestions add "Winnie the Pooh"`)
+	h.MustRun("oliver", "suggestions add Cars")
+	h.MustRun("oliver", "suggestions add Up")
+
+	h.Weekday(time.Thursday)
+	h.MustRun("liam", "votes cast 1 3 2")
+	h.MustRun("noah", "votes cast 1 3")
+	h.MustRun("oliver", "votes cast 3 1")
+
+	week := general.WeekIDFromTime(h.Clock.Now())
+	carried, err := vote.CarryOver(context.Background(), h.Stores.Suggestions, h.Stores.Votes, h.Stores.Seconds, h.Stores.History, nextWeek(t, h, h.Config), week, 2)
+	if err != nil || len(carried) != 2 {
+		t.Fatal(err, carried)
+	}
+
+	h.Clock.Advance(7 * 24 * time.Hour)
+	output := h.MustRun("liam", "suggestions list")
+
+	if strings.Contains(output, "Shrek") || !strings.Contains(output, "1   Cars") || !strings.Contains(output, "2   Winnie the Pooh") || carried[0].Author != "oliver" {
+		t.Fail()
+	}
+}
+
+func TestGivenLimitsTheRunnersUpCarriedOverStayWithinThem(t *testing.T) {
+	h := movtest.New(t)
+	h.MustRun("liam", "suggestions add Shrek")
+	h.MustRun("oliver", "suggestions add Cars")
+	h.MustRun("oliver", "suggestions add Up")
+	h.MustRun("noah", `suggestions add "Winnie the Pooh"`)
+	h.MustRun("liam", "suggestions add Brave")
+
+	h.Weekday(time.Thursday)
+	h.MustRun("liam", "votes cast 1 2 3 4 5")
+
+	week := general.WeekIDFromTime(h.Clock.Now())
+	cfg := h.Config
+	cfg.SuggestionLimit = 1
+	cfg.BallotLimit = 2
+	carried, err := vote.CarryOver(context.Background(), h.Stores.Suggestions, h.Stores.Votes, h.Stores.Seconds, h.Stores.History, nextWeek(t, h, cfg), week, -1)
+
+	// Up is past oliver's limit, the ballot is full before Brave
+	if err != nil || len(carried) != 2 || carried[0].Movie != "Cars" || carried[1].Movie != "Winnie the Pooh" {
+		t.Fail()
+	}
+}
+
+func TestGivenARunnerUpWatchedRecentlyTheNextBestIsCarriedOver(t *testing.T) {
+	h := movtest.New(t)
+	h.MustRun("noah", "suggestions add Cars")
+	h.MustRun("liam", "suggestions add Shrek")
+	h.MustRun("oliver", "suggestions add Up")
+	h.Weekday(time.Thursday)
+	h.MustRun("liam", "votes cast 1 2 3")
+
+	// Last week's winner, recorded late
+	week := general.WeekIDFromTime(h.Clock.Now())
+	watched, err := suggestion.NewSuggestion(week.Previous(), "emma", general.MovieFromString("Shrek"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.Stores.Suggestions.Save(context.Background(), *watched); err != nil {
+		t.Fatal(err)
+	}
+
+	shrek := vote.Candidate{ID: 1, Movie: watched.Movie, Author: watched.Author}
+	if err := h.Stores.Votes.SaveWinner(context.Background(), week.Previous(), shrek, "irv", h.Clock.Now().AddDate(0, 0, -7)); err != nil {
+		t.Fatal(err)
+	}
+
+	carried, err := vote.CarryOver(context.Background(), h.Stores.Suggestions, h.Stores.Votes, h.Stores.Seconds, h.Stores.History, nextWeek(t, h, h.Config), week, 1)
+
+	if err != nil || len(carried) != 1 || carried[0].Movie != "Up" {
+		t.Fail()
+	}
+}
+
+func TestGivenANominationThresholdOnlySecondedSuggestionsAreCounted(t *testing.T) {
+	h := movtest.New(t)
+	h.Config.NominationThreshold = 1
+	h.MustRun("liam", "suggestions add Shrek")
+	h.MustRun("noah", `suggestions add "Winnie the Pooh"`)
+	h.MustRun("noah", "suggestions second 1")
+
+	h.Weekday(time.Thursday)
+	refused := h.MustRun("liam", "votes cast 2 1")
+	h.MustRun("liam", "votes cast 1")
+
+	h.Weekday(time.Saturday)
+	output := h.MustRun("liam", "votes results")
+
+	if !strings.Contains(refused, "Suggestion 2 is not on the ballot.") || strings.Contains(output, "Pooh") || !strings.Contains(output, "Winner: #1 Shrek") {
+		t.Fail()
+	}
+}
+
+func TestGivenCastVotesMineShowsThemInOrder(t *testing.T) {
+	h := movtest.New(t)
+	h.MustRun("liam", "suggestions add Shrek")
+	h.MustRun("noah", "suggestions add Cars")
+
+	h.Weekday(time.Thursday)
+	h.MustRun("liam", "votes cast 2 1")
+	output := h.MustRun("liam", "votes mine")
+
+	if !strings.Contains(output, "1. #2 Cars\n2. #1 Shrek\n") || !strings.Contains(h.MustRun("noah", "votes mine"), "haven't cast any votes") {
+		t.Fail()
+	}
+}
+
+func TestGivenWithdrawnVotesTheyAreNotCounted(t *testing.T) {
+	h := movtest.New(t)
+	h.MustRun("liam", "suggestions add Shrek")
+	h.MustRun("noah", "suggestions add Cars")
+
+	h.Weekday(time.Thursday)
+	h.MustRun("liam", "votes cast 1")
+	h.MustRun("noah", "votes cast 2")
+	output := h.MustRun("liam", "votes withdraw")
+	mine := h.MustRun("liam", "votes mine")
+
+	h.Weekday(time.Saturday)
+	closed := h.MustRun("noah", "votes withdraw")
+	results := h.MustRun("noah", "votes results")
+
+	if !strings.Contains(output, "withdrawn") || !strings.Contains(mine, "haven't cast") || !strings.Contains(closed, "unable to withdraw votes") || !strings.Contains(results, "Winner: #2 Cars") {
+		t.Fail()
+	}
+}
+
+func TestGivenARecordedWinnerRecordingTheWeekAgainIsRefused(t *testing.T) {
+	h := movtest.New(t)
+	h.Config.Admins = []string{"olivia"}
+	h.MustRun("liam", "suggestions add Shrek")
+	h.MustRun("noah", "suggestions add Cars")
+	h.Weekday(time.Thursday)
+	h.MustRun("liam", "votes cast 1")
+
+	early := h.MustRun("olivia", "votes record")
+	h.Weekday(time.Friday)
+	member := h.MustRun("liam", "votes record")
+	recorded := h.MustRun("olivia", "votes record")
+	again := h.MustRun("olivia", "votes record")
+
+	if !strings.Contains(early, "until the vote period has ended") || !strings.Contains(member, "need to be an admin") ||
+		!strings.Contains(recorded, "Recorded #1 Shrek as the winner of week 202114.") || !strings.Contains(again, "already recorded") {
+		t.Fail()
+	}
+}
+
+func TestGivenAVoteForAnUnknownSuggestionItIsReported(t *testing.T) {
+	h := movtest.New(t)
+	h.MustRun("liam", "suggestions add Shrek")
+
+	h.Weekday(time.Thursday)
+	output, err := h.Run("liam", "votes cast 1 99")
+
+	if err == nil || !strings.Contains(output, "Suggestion 99 does not exist.") || strings.Contains(h.MustRun("liam", "votes mine"), "Shrek") {
+		t.Fail()
+	}
+}
@@ -0,0 +1,118 @@
+package vote
+
+import (
+	"sort"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/suggestion"
+)
+
+// InstantRunoff counts first preferences round by round. When a candidate
+// holds a majority of the ballots still in play they win, otherwise the
+// candidate with the fewest votes is eliminated and their ballots are
+// transferred to the next preference. Candidates nobody ranked first are all
+// eliminated together.
+type InstantRunoff struct{}
+
+func (InstantRunoff) Tally(candidates []Candidate, ballots []Ballot, ties TieBreaker) Result {
+	result := Result{
+		Method:     "instant-runoff",
+		Unit:       "Votes",
+		Candidates: candidates,
+	}
+
+	if len(candidates) == 0 || len(ballots) == 0 {
+		return result
+	}
+
+	active := make(map[suggestion.OrderedID]bool, len(candidates))
+	for _, c := range candidates {
+		active[c.ID] = true
+	}
+
+	for roundNumber := 1; len(active) > 0; roundNumber++ {
+		round := Round{
+			Number: roundNumber,
+			Scores: make(map[suggestion.OrderedID]int, len(active)),
+		}
+
+		for id := range active {
+			round.Scores[id] = 0
+		}
+
+		for _, b := range ballots {
+			if id, ok := topPreference(b, active); ok {
+				round.Scores[id]++
+			} else {
+				round.Exhausted++
+			}
+		}
+
+		remaining := sortedIDs(active)
+		continuing := len(ballots) - round.Exhausted
+
+		if len(remaining) == 1 {
+			result.Rounds = append(result.Rounds, round)
+			result.Winner = result.Candidate(remaining[0])
+			return result
+		}
+
+		for _, id := range remaining {
+			if round.Scores[id]*2 > continuing {
+				result.Rounds = append(result.Rounds, round)
+				result.Winner = result.Candidate(id)
+				return result
+			}
+		}
+
+		fewest := round.Scores[remaining[0]]
+		for _, id := range remaining {
+			if round.Scores[id] < fewest {
+				fewest = round.Scores[id]
+			}
+		}
+
+		var lowest []suggestion.OrderedID
+		for _, id := range remaining {
+			if round.Scores[id] == fewest {
+				lowest = append(lowest, id)
+			}
+		}
+
+		if fewest == 0 && len(lowest) < len(remaining) {
+			round.Eliminated = lowest
+		} else {
+			round.Eliminated = []suggestion.OrderedID{
+				result.breakTie(roundNumber, lowest, ties, false),
+			}
+		}
+
+		for _, id := range round.Eliminated {
+			delete(active, id)
+		}
+
+		result.Rounds = append(result.Rounds, round)
+	}
+
+	return result
+}
+
+func topPreference(b Ballot, active map[suggestion.OrderedID]bool) (suggestion.OrderedID, bool) {
+	for _, id := range b.Ranking {
+		if active[id] {
+			return id, true
+		}
+	}
+
+	return 0, false
+}
+
+func sortedIDs(set map[suggestion.OrderedID]bool) []suggestion.OrderedID {
+	ids := make([]suggestion.OrderedID, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
@@ -0,0 +1,99 @@
+package vote
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGivenAMajorityOfFirstPreferencesInstantRunoffWinsInFirstRound(t *testing.T) {
+	ballots := []Ballot{
+		ballot("liam", 1, 2, 3),
+		ballot("noah", 3, 2, 1),
+		ballot("oliver", 1, 3, 2),
+		ballot("william", 2, 1, 3),
+		ballot("james", 1, 2, 3),
+		ballot("sneaky", 1, 2),
+	}
+
+	actual := InstantRunoff{}.Tally(testCandidates(), ballots, EarliestSuggestion{})
+
+	if actual.Winner == nil || actual.Winner.ID != 1 || len(actual.Rounds) != 1 {
+		t.Fail()
+	}
+}
+
+func TestGivenNoMajorityInstantRunoffTransfersEliminatedVotes(t *testing.T) {
+	ballots := []Ballot{
+		ballot("liam", 1, 2),
+		ballot("noah", 1, 3),
+		ballot("oliver", 2, 1),
+		ballot("william", 2, 3),
+		ballot("james", 3, 2),
+	}
+
+	actual := InstantRunoff{}.Tally(testCandidates(), ballots, EarliestSuggestion{})
+
+	if actual.Winner == nil || actual.Winner.ID != 2 || len(actual.Rounds) != 2 {
+		t.FailNow()
+	}
+
+	eliminated := actual.Rounds[0].Eliminated
+	if len(eliminated) != 1 || eliminated[0] != 3 {
+		t.Fail()
+	}
+
+	if actual.Rounds[1].Scores[2] != 3 {
+		t.Fail()
+	}
+}
+
+func TestGivenBallotsThatRunOutOfPreferencesTheyAreExhausted(t *testing.T) {
+	ballots := []Ballot{
+		ballot("liam", 1),
+		ballot("noah", 2),
+		ballot("oliver", 2),
+		ballot("william", 3),
+		ballot("james", 3, 1),
+	}
+
+	actual := InstantRunoff{}.Tally(testCandidates(), ballots, EarliestSuggestion{})
+
+	if len(actual.Rounds) != 3 || actual.Rounds[1].Exhausted != 1 {
+		t.FailNow()
+	}
+
+	if actual.Winner == nil || actual.Winner.ID != 2 {
+		t.Fail()
+	}
+}
+
+func TestGivenNoBallotsInstantRunoffHasNoWinner(t *testing.T) {
+	actual := InstantRunoff{}.Tally(testCandidates(), nil, EarliestSuggestion{})
+
+	if actual.Winner != nil || len(actual.Rounds) != 0 {
+		t.Fail()
+	}
+}
+
+func TestGivenATieForLastInstantRunoffEliminatesTheLeastFavoured(t *testing.T) {
+	candidates := testCandidates()
+	candidates[0].DateAdded = time.Date(2021, 5, 26, 0, 0, 0, 0, time.UTC)
+	candidates[1].DateAdded = time.Date(2021, 5, 24, 0, 0, 0, 0, time.UTC)
+	candidates[2].DateAdded = time.Date(2021, 5, 25, 0, 0, 0, 0, time.UTC)
+
+	ballots := []Ballot{
+		ballot("liam", 1, 3),
+		ballot("noah", 2, 3),
+		ballot("oliver", 3, 2),
+	}
+
+	actual := InstantRunoff{}.Tally(candidates, ballots, EarliestSuggestion{})
+
+	if len(actual.Ties) != 1 || actual.Ties[0].Chosen != 1 || actual.Rounds[0].Eliminated[0] != 1 {
+		t.FailNow()
+	}
+
+	if actual.Winner == nil || actual.Winner.ID != 3 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,38 @@
+package vote
+
+// Plurality only looks at each ballot's first preference. The movie named
+// first by the most people wins, even without a majority.
+type Plurality struct{}
+
+func (Plurality) Tally(candidates []Candidate, ballots []Ballot, ties TieBreaker) Result {
+	result := Result{
+		Method:     "plurality",
+		Unit:       "Votes",
+		Candidates: candidates,
+	}
+
+	if len(candidates) == 0 || len(ballots) == 0 {
+		return result
+	}
+
+	round := newRound(1, candidates)
+
+	for _, b := range ballots {
+		counted := false
+		for _, id := range b.Ranking {
+			if _, exists := round.Scores[id]; exists {
+				round.Scores[id]++
+				counted = true
+				break
+			}
+		}
+
+		if !counted {
+			round.Exhausted++
+		}
+	}
+
+	result.decideHighest(round, ties)
+
+	return result
+}
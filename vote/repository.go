@@ -1,16 +1,20 @@
 package vote
 
 import (
+	"context"
 	"database/sql"
-	"log"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+	"github.com/fredlawl/200-colony-movie-night-bot/suggestion"
 )
 
+// Repository is the SQL Store, for SQLite and PostgreSQL.
 type Repository struct {
-	session *sql.DB
+	session *storage.DB
 }
 
 type BulkVoteResult struct {
@@ -18,53 +22,46 @@ type BulkVoteResult struct {
 	vote Vote
 }
 
-func NewRepository(session *sql.DB) *Repository {
+// NewBulkVoteResult reports whether a vote was saved, for stores outside of
+// this package.
+func NewBulkVoteResult(vote Vote, err error) BulkVoteResult {
+	return BulkVoteResult{
+		err:  err,
+		vote: vote,
+	}
+}
+
+func NewRepository(session *storage.DB) *Repository {
 	return &Repository{
 		session: session,
 	}
 }
 
-func (context *Repository) BulkSaveVotes(author string, week general.WeekID, votes []Vote) ([]BulkVoteResult, error) {
+func (context *Repository) BulkSaveVotes(ctx context.Context, author string, week general.WeekID, votes []Vote) ([]BulkVoteResult, error) {
 	emptyBulkResult := []BulkVoteResult{}
 
 	if len(votes) == 0 {
 		return emptyBulkResult, nil
 	}
 
-	tx, err := context.session.Begin()
+	tx, err := context.session.BeginTx(ctx, nil)
 	if err != nil {
 		return emptyBulkResult, errors.Wrap(err, "")
 	}
 
-	truncateStmt, err := context.session.Prepare(`DELETE FROM votes WHERE weekID = ? AND author = ?`)
-	if err != nil {
-		tx.Rollback()
-		return emptyBulkResult, errors.Wrap(err, "")
-	}
-
-	_, truncateErr := tx.Stmt(truncateStmt).Exec(week.String(), author)
+	// Statements run on the transaction's connection, asking the pool for
+	// another would deadlock a pool limited to one connection
+	_, truncateErr := tx.ExecContext(ctx, `DELETE FROM votes WHERE weekID = ? AND author = ?`, week.String(), author)
 	if truncateErr != nil {
 		tx.Rollback()
 		return emptyBulkResult, errors.Wrap(truncateErr, "")
 	}
 
-	// TODO: Figure out how to BULK insert w/ prepared statement
-	stmt, err := context.session.Prepare(`
-		INSERT INTO votes (suggestionID, weekID, author, preference)
-		VALUES (?, ?, ?, ?)
-	`)
-	if err != nil {
-		tx.Rollback()
-		return emptyBulkResult, errors.Wrap(err, "")
-	}
-
 	var hasErrors = false
 	var bulkResults = make([]BulkVoteResult, len(votes))
 	for i, v := range votes {
 		bulkResults[i].vote = v
-		_, bulkResults[i].err = tx.Stmt(stmt).Exec(v.SuggestionOrderedID,
-			v.WeekID.String(), v.Author, v.Preference)
-		bulkResults[i].err = errors.Wrap(bulkResults[i].err, "")
+		bulkResults[i].err = insertVote(ctx, tx, v)
 		if !hasErrors {
 			hasErrors = bulkResults[i].err != nil
 		}
@@ -77,19 +74,175 @@ func (context *Repository) BulkSaveVotes(author string, week general.WeekID, vot
 	return bulkResults, tx.Commit()
 }
 
-func (context *Repository) SuggestionCnt(weekID general.WeekID) int {
-	stmt, err := context.session.Prepare("SELECT COUNT(id) FROM suggestions WHERE weekID = ?")
+// insertVote saves a vote for the suggestion numbered within the vote's week.
+func insertVote(ctx context.Context, tx *storage.Tx, v Vote) error {
+	var suggestionID int
+	err := tx.QueryRowContext(ctx, `SELECT id FROM suggestions WHERE weekID = ? AND number = ?`,
+		v.WeekID.String(), v.SuggestionOrderedID).Scan(&suggestionID)
+	if err == sql.ErrNoRows {
+		return suggestion.ErrUnknownSuggestion
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO votes (suggestionID, weekID, author, preference)
+		VALUES (?, ?, ?, ?)
+	`, suggestionID, v.WeekID.String(), v.Author, v.Preference)
+	if errors.Is(err, storage.ErrForeignKeyViolation) {
+		return suggestion.ErrUnknownSuggestion
+	}
+
+	return errors.Wrap(err, "")
+}
+
+func (context *Repository) WithdrawVotes(ctx context.Context, author string, week general.WeekID) (int, error) {
+	result, err := context.session.ExecContext(ctx, `DELETE FROM votes WHERE weekID = ? AND author = ?`, week.String(), author)
 	if err != nil {
-		log.Fatalf("[error] %+v\n", errors.Wrap(err, ""))
-		return 0
+		return 0, errors.Wrap(err, "")
 	}
 
+	withdrawn, err := result.RowsAffected()
+	return int(withdrawn), errors.Wrap(err, "")
+}
+
+func (context *Repository) SuggestionCnt(ctx context.Context, weekID general.WeekID) (int, error) {
 	var cnt int
-	queryErr := stmt.QueryRow(weekID.String()).Scan(&cnt)
-	if queryErr != nil {
-		log.Fatalf("[error] %+v", errors.Wrap(err, ""))
-		return 0
+	err := context.session.QueryRowContext(ctx, "SELECT COUNT(id) FROM suggestions WHERE weekID = ?", weekID.String()).Scan(&cnt)
+
+	return cnt, errors.Wrap(err, "")
+}
+
+// Candidates returns every suggestion of the week that can be voted on along
+// with how many earlier weeks each author has won.
+func (context *Repository) Candidates(ctx context.Context, weekID general.WeekID) ([]Candidate, error) {
+	rows, err := context.session.QueryContext(ctx, `
+		SELECT s.number, s.movie, s.movieID, s.author, s.dateAdded, COUNT(w.weekID)
+		FROM suggestions s
+		LEFT JOIN winners w
+			ON w.author = s.author
+			AND w.weekID < s.weekID
+		WHERE s.weekID = ?
+		GROUP BY s.number, s.movie, s.movieID, s.author, s.dateAdded
+		ORDER BY s.number ASC
+	`, weekID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	defer rows.Close()
+
+	var candidates []Candidate
+	for rows.Next() {
+		var id int
+		var movie string
+		var movieID sql.NullString
+		var c Candidate
+		if err := rows.Scan(&id, &movie, &movieID, &c.Author, &c.DateAdded, &c.PriorWins); err != nil {
+			return nil, errors.Wrap(err, "")
+		}
+
+		c.ID = suggestion.OrderedID(id)
+		c.Movie = general.MovieFromString(movie)
+		c.MovieID = movieID.String
+		candidates = append(candidates, c)
+	}
+
+	return candidates, errors.Wrap(rows.Err(), "")
+}
+
+// SaveWinner records the week's winning suggestion. A week is only recorded
+// once, see RecordWinner.
+func (context *Repository) SaveWinner(ctx context.Context, weekID general.WeekID, winner Candidate, method string, watched time.Time) error {
+	result, err := context.session.ExecContext(ctx, `
+		INSERT INTO winners (weekID, suggestionID, author, movie, movieID, method, dateDecided)
+		SELECT weekID, id, ?, ?, movieID, ?, ?
+		FROM suggestions
+		WHERE weekID = ? AND number = ?
+	`, winner.Author, winner.Movie.String(), method, watched, weekID.String(), winner.ID)
+	if errors.Is(err, storage.ErrUniqueViolation) {
+		return ErrWinnerRecorded
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	// The suggestion was removed since the tally
+	saved, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "")
+	}
+
+	if saved == 0 {
+		return suggestion.ErrUnknownSuggestion
+	}
+
+	return nil
+}
+
+// Ballots returns each author's votes for the week ranked by preference.
+func (context *Repository) Ballots(ctx context.Context, weekID general.WeekID) ([]Ballot, error) {
+	rows, err := context.session.QueryContext(ctx, `
+		SELECT v.author, s.number
+		FROM votes v
+		INNER JOIN suggestions s
+			ON s.id = v.suggestionID
+		WHERE v.weekID = ?
+		ORDER BY v.author ASC, v.preference ASC
+	`, weekID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	defer rows.Close()
+
+	var ballots []Ballot
+	for rows.Next() {
+		var author string
+		var id int
+		if err := rows.Scan(&author, &id); err != nil {
+			return nil, errors.Wrap(err, "")
+		}
+
+		if len(ballots) == 0 || ballots[len(ballots)-1].Author != author {
+			ballots = append(ballots, Ballot{Author: author})
+		}
+
+		last := &ballots[len(ballots)-1]
+		last.Ranking = append(last.Ranking, suggestion.OrderedID(id))
+	}
+
+	return ballots, errors.Wrap(rows.Err(), "")
+}
+
+func (context *Repository) Ballot(ctx context.Context, author string, week general.WeekID) (*Ballot, error) {
+	rows, err := context.session.QueryContext(ctx, `
+		SELECT s.number
+		FROM votes v
+		INNER JOIN suggestions s
+			ON s.id = v.suggestionID
+		WHERE v.weekID = ? AND v.author = ?
+		ORDER BY v.preference ASC
+	`, week.String(), author)
+	if err != nil {
+		return nil, errors.Wrap(err, "")
+	}
+	defer rows.Close()
+
+	var ballot *Ballot
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.Wrap(err, "")
+		}
+
+		if ballot == nil {
+			ballot = &Ballot{Author: author}
+		}
+
+		ballot.Ranking = append(ballot.Ranking, suggestion.OrderedID(id))
 	}
 
-	return cnt
+	return ballot, errors.Wrap(rows.Err(), "")
 }
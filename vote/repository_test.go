@@ -0,0 +1,245 @@
+package vote_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/dbtest"
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/history"
+	"github.com/fredlawl/200-colony-movie-night-bot/storage"
+	"github.com/fredlawl/200-colony-movie-night-bot/suggestion"
+	"github.com/fredlawl/200-colony-movie-night-bot/vote"
+)
+
+var week = general.WeekID{IsoYear: 2021, IsoWeek: 14}
+
+// suggest saves the movies as the week's suggestions, numbered in order.
+func suggest(t *testing.T, session *storage.DB, weekID general.WeekID, movies ...string) []suggestion.Suggestion {
+	suggestions := suggestion.NewRepository(session)
+
+	var saved []suggestion.Suggestion
+	for _, movie := range movies {
+		s, err := suggestion.NewSuggestion(weekID, "liam", general.MovieFromString(movie))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := suggestions.Save(context.Background(), *s); err != nil {
+			t.Fatal(err)
+		}
+
+		saved = append(saved, *s)
+	}
+
+	return saved
+}
+
+// ranking returns the author's votes for the suggestions, most preferred
+// first.
+func ranking(author string, ids ...suggestion.OrderedID) []vote.Vote {
+	votes := make([]vote.Vote, len(ids))
+	for i, id := range ids {
+		votes[i] = vote.Vote{SuggestionOrderedID: id, WeekID: week, Author: author, Preference: uint(i + 1)}
+	}
+
+	return votes
+}
+
+func TestGivenBulkSavedVotesTheBallotsRankThem(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, session *storage.DB) {
+		suggest(t, session, week, "Shrek", "Cars", "Up")
+		votes := vote.NewRepository(session)
+		ctx := context.Background()
+
+		if _, err := votes.BulkSaveVotes(ctx, "noah", week, ranking("noah", 3, 1)); err != nil {
+			t.Fatal(err)
+		}
+
+		// Casting again replaces the ballot
+		if _, err := votes.BulkSaveVotes(ctx, "liam", week, ranking("liam", 1)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := votes.BulkSaveVotes(ctx, "liam", week, ranking("liam", 2, 3)); err != nil {
+			t.Fatal(err)
+		}
+
+		ballots, err := votes.Ballots(ctx, week)
+		if err != nil || len(ballots) != 2 {
+			t.Fatalf("expected 2 ballots, got %v %+v", ballots, err)
+		}
+
+		noah, err := votes.Ballot(ctx, "noah", week)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		liam, err := votes.Ballot(ctx, "liam", week)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(noah.Ranking) != 2 || noah.Ranking[0] != 3 || noah.Ranking[1] != 1 ||
+			len(liam.Ranking) != 2 || liam.Ranking[0] != 2 || liam.Ranking[1] != 3 {
+			t.Fail()
+		}
+	})
+}
+
+func TestGivenAVoteForAnUnknownSuggestionNoneOfTheBallotIsSaved(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, session *storage.DB) {
+		suggest(t, session, week, "Shrek")
+		votes := vote.NewRepository(session)
+		ctx := context.Background()
+
+		if _, err := votes.BulkSaveVotes(ctx, "noah", week, ranking("noah", 1, 42)); err != nil {
+			t.Fatal(err)
+		}
+
+		ballot, err := votes.Ballot(ctx, "noah", week)
+		if err != nil || ballot != nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestGivenAnEarlierWeekVotesAreForTheWeeksNumbers(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, session *storage.DB) {
+		// The previous week's suggestions take up the first database ids
+		suggest(t, session, week.Previous(), "Up")
+		suggest(t, session, week, "Shrek", "Cars")
+		votes := vote.NewRepository(session)
+		ctx := context.Background()
+
+		if _, err := votes.BulkSaveVotes(ctx, "noah", week, ranking("noah", 2)); err != nil {
+			t.Fatal(err)
+		}
+
+		candidates, err := votes.Candidates(ctx, week)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ballots, err := votes.Ballots(ctx, week)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(candidates) != 2 || candidates[1].ID != 2 || candidates[1].Movie != "Cars" ||
+			len(ballots) != 1 || len(ballots[0].Ranking) != 1 || ballots[0].Ranking[0] != 2 {
+			t.Fail()
+		}
+	})
+}
+
+func TestGivenARenamedSuggestionItsVotesAreKept(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, session *storage.DB) {
+		shrke := suggest(t, session, week, "Shrke", "Cars")[0]
+		votes := vote.NewRepository(session)
+		ctx := context.Background()
+
+		if _, err := votes.BulkSaveVotes(ctx, "noah", week, ranking("noah", 1, 2)); err != nil {
+			t.Fatal(err)
+		}
+
+		shrke.Movie = general.MovieFromString("Shrek")
+		if err := suggestion.NewRepository(session).Rename(ctx, shrke, "liam"); err != nil {
+			t.Fatal(err)
+		}
+
+		candidates, err := votes.Candidates(ctx, week)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ballot, err := votes.Ballot(ctx, "noah", week)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if candidates[0].Movie != "Shrek" || ballot == nil || len(ballot.Ranking) != 2 || ballot.Ranking[0] != 1 {
+			t.Fail()
+		}
+	})
+}
+
+func TestGivenWithdrawnVotesTheBallotIsEmpty(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, session *storage.DB) {
+		suggest(t, session, week, "Shrek", "Cars")
+		votes := vote.NewRepository(session)
+		ctx := context.Background()
+
+		if _, err := votes.BulkSaveVotes(ctx, "noah", week, ranking("noah", 2, 1)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := votes.BulkSaveVotes(ctx, "liam", week, ranking("liam", 1)); err != nil {
+			t.Fatal(err)
+		}
+
+		withdrawn, err := votes.WithdrawVotes(ctx, "noah", week)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		noah, err := votes.Ballot(ctx, "noah", week)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ballots, err := votes.Ballots(ctx, week)
+
+		if withdrawn != 2 || noah != nil || err != nil || len(ballots) != 1 || ballots[0].Author != "liam" {
+			t.Fail()
+		}
+	})
+}
+
+func TestGivenARecordedWinnerSavingTheWeekAgainIsRefused(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, session *storage.DB) {
+		suggest(t, session, week, "Shrek", "Cars")
+		votes := vote.NewRepository(session)
+		ctx := context.Background()
+
+		candidates, err := votes.Candidates(ctx, week)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		watched := time.Date(2021, 4, 9, 19, 0, 0, 0, time.UTC)
+		if err := votes.SaveWinner(ctx, week, candidates[0], "irv", watched); err != nil {
+			t.Fatal(err)
+		}
+
+		again := votes.SaveWinner(ctx, week, candidates[1], "irv", watched.Add(time.Hour))
+
+		if !errors.Is(again, vote.ErrWinnerRecorded) {
+			t.Fail()
+		}
+	})
+}
+
+func TestGivenARemovedSuggestionSavingItAsTheWinnerFails(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, session *storage.DB) {
+		shrek := suggest(t, session, week, "Shrek")[0]
+		votes := vote.NewRepository(session)
+		ctx := context.Background()
+
+		candidates, err := votes.Candidates(ctx, week)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := suggestion.NewRepository(session).Remove(ctx, shrek); err != nil {
+			t.Fatal(err)
+		}
+
+		saveErr := votes.SaveWinner(ctx, week, candidates[0], "irv", time.Date(2021, 4, 9, 19, 0, 0, 0, time.UTC))
+		watched, err := history.NewRepository(session).Watched(ctx)
+
+		if !errors.Is(saveErr, suggestion.ErrUnknownSuggestion) || err != nil || len(watched) != 0 {
+			t.Fail()
+		}
+	})
+}
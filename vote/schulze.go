@@ -0,0 +1,118 @@
+package vote
+
+import "github.com/fredlawl/200-colony-movie-night-bot/suggestion"
+
+// Schulze is a Condorcet method. Every pair of candidates is compared by how
+// many ballots rank one above the other, then the strongest chain of pairwise
+// victories between each pair decides who beats whom. A candidate that beats
+// everyone head to head always wins.
+//
+// The score reported for each candidate is the number of other candidates it
+// beats by strongest path.
+type Schulze struct{}
+
+func (Schulze) Tally(candidates []Candidate, ballots []Ballot, ties TieBreaker) Result {
+	result := Result{
+		Method:     "Schulze",
+		Unit:       "Wins",
+		Candidates: candidates,
+	}
+
+	if len(candidates) == 0 || len(ballots) == 0 {
+		return result
+	}
+
+	n := len(candidates)
+	index := make(map[suggestion.OrderedID]int, n)
+	positions := make(map[int]int, n)
+	for i, c := range candidates {
+		index[c.ID] = i
+	}
+
+	// preferred[i][j] is the number of ballots ranking candidate i above j.
+	// Ranked candidates are preferred over the ones left off the ballot.
+	preferred := newMatrix(n)
+	for _, b := range ballots {
+		for k := range positions {
+			delete(positions, k)
+		}
+
+		for rank, id := range b.Ranking {
+			i, exists := index[id]
+			if _, seen := positions[i]; exists && !seen {
+				positions[i] = rank
+			}
+		}
+
+		for i := 0; i < n; i++ {
+			rankI, rankedI := positions[i]
+			if !rankedI {
+				continue
+			}
+
+			for j := 0; j < n; j++ {
+				rankJ, rankedJ := positions[j]
+				if i != j && (!rankedJ || rankI < rankJ) {
+					preferred[i][j]++
+				}
+			}
+		}
+	}
+
+	strength := newMatrix(n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i != j && preferred[i][j] > preferred[j][i] {
+				strength[i][j] = preferred[i][j]
+			}
+		}
+	}
+
+	for k := 0; k < n; k++ {
+		for i := 0; i < n; i++ {
+			if i == k {
+				continue
+			}
+
+			for j := 0; j < n; j++ {
+				if j == i || j == k {
+					continue
+				}
+
+				if through := minInt(strength[i][k], strength[k][j]); through > strength[i][j] {
+					strength[i][j] = through
+				}
+			}
+		}
+	}
+
+	round := newRound(1, candidates)
+	for i, c := range candidates {
+		for j := 0; j < n; j++ {
+			if i != j && strength[i][j] > strength[j][i] {
+				round.Scores[c.ID]++
+			}
+		}
+	}
+
+	result.decideHighest(round, ties)
+
+	return result
+}
+
+func newMatrix(n int) [][]int {
+	matrix := make([][]int, n)
+	for i := range matrix {
+		matrix[i] = make([]int, n)
+	}
+
+	return matrix
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,32 @@
+package vote
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+)
+
+// ErrWinnerRecorded is returned when the week's winner was already recorded.
+var ErrWinnerRecorded = errors.New("the week's winner was already recorded")
+
+// Store keeps the votes and winners of each week.
+type Store interface {
+	// BulkSaveVotes replaces the author's votes for the week. Votes for
+	// suggestions that don't exist fail with suggestion.ErrUnknownSuggestion.
+	BulkSaveVotes(ctx context.Context, author string, week general.WeekID, votes []Vote) ([]BulkVoteResult, error)
+	// WithdrawVotes removes the author's votes for the week, returning how
+	// many there were.
+	WithdrawVotes(ctx context.Context, author string, week general.WeekID) (int, error)
+	SuggestionCnt(ctx context.Context, weekID general.WeekID) (int, error)
+	Candidates(ctx context.Context, weekID general.WeekID) ([]Candidate, error)
+	// SaveWinner records the week's winner as watched at the given time,
+	// failing with ErrWinnerRecorded when it already was and with
+	// suggestion.ErrUnknownSuggestion when the winner no longer exists.
+	SaveWinner(ctx context.Context, weekID general.WeekID, winner Candidate, method string, watched time.Time) error
+	Ballots(ctx context.Context, weekID general.WeekID) ([]Ballot, error)
+	// Ballot returns the author's votes for the week ranked by preference,
+	// nil when they didn't vote.
+	Ballot(ctx context.Context, author string, week general.WeekID) (*Ballot, error)
+}
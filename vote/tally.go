@@ -0,0 +1,179 @@
+package vote
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/suggestion"
+)
+
+const (
+	InstantRunoffMethod = "irv"
+	BordaMethod         = "borda"
+	SchulzeMethod       = "schulze"
+	PluralityMethod     = "plurality"
+)
+
+// Tallier counts a week's ranked ballots and decides the winner. Whenever the
+// count can't separate candidates the tie breaker decides.
+type Tallier interface {
+	Tally(candidates []Candidate, ballots []Ballot, ties TieBreaker) Result
+}
+
+var talliers = map[string]Tallier{
+	InstantRunoffMethod: InstantRunoff{},
+	BordaMethod:         Borda{},
+	SchulzeMethod:       Schulze{},
+	PluralityMethod:     Plurality{},
+}
+
+// NewTallier looks up the counting method by the name used in the app
+// configuration and the --method flag.
+func NewTallier(method string) (Tallier, error) {
+	tallier, exists := talliers[strings.ToLower(method)]
+	if !exists {
+		return nil, fmt.Errorf("unknown counting method \"%s\", expected one of %s",
+			method, strings.Join(TallyMethods(), ", "))
+	}
+
+	return tallier, nil
+}
+
+// TallyMethods lists the names accepted by NewTallier.
+func TallyMethods() []string {
+	methods := make([]string, 0, len(talliers))
+	for name := range talliers {
+		methods = append(methods, name)
+	}
+
+	sort.Strings(methods)
+
+	return methods
+}
+
+// Ballot is a single user's ranked votes for a week, most preferred first.
+type Ballot struct {
+	Author  string
+	Ranking []suggestion.OrderedID
+}
+
+// Candidate is a suggestion that can be voted on.
+type Candidate struct {
+	ID        suggestion.OrderedID
+	Movie     general.Movie
+	MovieID   string // Catalog id of the movie, empty when unknown
+	Author    string
+	DateAdded time.Time
+	PriorWins int // Number of past weeks the author's suggestion won
+}
+
+// Round is a single pass of scoring the candidates that have not yet been
+// eliminated. Methods that decide in one pass only have a single round.
+type Round struct {
+	Number     int
+	Scores     map[suggestion.OrderedID]int
+	Exhausted  int
+	Eliminated []suggestion.OrderedID
+}
+
+// Tie records how a tie between candidates was broken, either to pick the
+// winner or to pick who is eliminated.
+type Tie struct {
+	Round     int
+	Tied      []suggestion.OrderedID
+	Chosen    suggestion.OrderedID
+	Rule      string
+	ForWinner bool
+}
+
+type Result struct {
+	Method     string
+	Unit       string
+	Candidates []Candidate
+	Rounds     []Round
+	Winner     *Candidate
+	Ties       []Tie
+}
+
+// Candidate returns the candidate with the given ID, or nil when the ID is
+// not part of the result.
+func (result *Result) Candidate(id suggestion.OrderedID) *Candidate {
+	for i := range result.Candidates {
+		if result.Candidates[i].ID == id {
+			return &result.Candidates[i]
+		}
+	}
+
+	return nil
+}
+
+// decideHighest picks the candidate with the best score of a single round
+// tally.
+func (result *Result) decideHighest(round Round, ties TieBreaker) {
+	result.Rounds = append(result.Rounds, round)
+
+	var best []suggestion.OrderedID
+	for _, c := range result.Candidates {
+		switch {
+		case len(best) == 0 || round.Scores[c.ID] > round.Scores[best[0]]:
+			best = []suggestion.OrderedID{c.ID}
+		case round.Scores[c.ID] == round.Scores[best[0]]:
+			best = append(best, c.ID)
+		}
+	}
+
+	if len(best) == 0 {
+		return
+	}
+
+	result.Winner = result.Candidate(result.breakTie(round.Number, best, ties, true))
+}
+
+// breakTie returns the tied candidate the tie breaker favours most when
+// picking a winner, or favours least when picking who is eliminated.
+// Candidates that aren't actually tied are returned as is.
+func (result *Result) breakTie(roundNumber int, tied []suggestion.OrderedID, ties TieBreaker, forWinner bool) suggestion.OrderedID {
+	if len(tied) == 1 {
+		return tied[0]
+	}
+
+	candidates := make([]Candidate, 0, len(tied))
+	for _, id := range tied {
+		candidates = append(candidates, *result.Candidate(id))
+	}
+
+	ordered := ties.Order(candidates)
+	chosen := ordered[len(ordered)-1].ID
+	if forWinner {
+		chosen = ordered[0].ID
+	}
+
+	sorted := append([]suggestion.OrderedID(nil), tied...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	result.Ties = append(result.Ties, Tie{
+		Round:     roundNumber,
+		Tied:      sorted,
+		Chosen:    chosen,
+		Rule:      ties.Rule(),
+		ForWinner: forWinner,
+	})
+
+	return chosen
+}
+
+func newRound(number int, candidates []Candidate) Round {
+	round := Round{
+		Number: number,
+		Scores: make(map[suggestion.OrderedID]int, len(candidates)),
+	}
+
+	for _, c := range candidates {
+		round.Scores[c.ID] = 0
+	}
+
+	return round
+}
@@ -0,0 +1,77 @@
+package vote
+
+import (
+	"testing"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/suggestion"
+)
+
+func testCandidates() []Candidate {
+	return []Candidate{
+		{ID: 1, Movie: "test"},
+		{ID: 2, Movie: "shreck"},
+		{ID: 3, Movie: "pooh"},
+	}
+}
+
+func ballot(author string, ranking ...suggestion.OrderedID) Ballot {
+	return Ballot{Author: author, Ranking: ranking}
+}
+
+// The second choice of both camps is the movie everyone can live with, but it
+// has the fewest first preferences.
+func centerSqueezeBallots() []Ballot {
+	return []Ballot{
+		ballot("liam", 1, 2, 3),
+		ballot("noah", 1, 2, 3),
+		ballot("oliver", 1, 2, 3),
+		ballot("william", 3, 2, 1),
+		ballot("james", 3, 2, 1),
+		ballot("benjamin", 3, 2, 1),
+		ballot("lucas", 2, 1, 3),
+		ballot("henry", 2, 1, 3),
+	}
+}
+
+func TestGivenACenterSqueezeInstantRunoffEliminatesTheCompromise(t *testing.T) {
+	actual := InstantRunoff{}.Tally(testCandidates(), centerSqueezeBallots(), EarliestSuggestion{})
+
+	if actual.Winner == nil || actual.Winner.ID != 1 {
+		t.Fail()
+	}
+}
+
+func TestGivenACenterSqueezeSchulzePicksTheCondorcetWinner(t *testing.T) {
+	actual := Schulze{}.Tally(testCandidates(), centerSqueezeBallots(), EarliestSuggestion{})
+
+	if actual.Winner == nil || actual.Winner.ID != 2 || actual.Rounds[0].Scores[2] != 2 {
+		t.Fail()
+	}
+}
+
+func TestGivenACenterSqueezeBordaPicksTheCompromise(t *testing.T) {
+	actual := Borda{}.Tally(testCandidates(), centerSqueezeBallots(), EarliestSuggestion{})
+
+	scores := actual.Rounds[0].Scores
+	if actual.Winner == nil || actual.Winner.ID != 2 || scores[1] != 8 || scores[2] != 10 || scores[3] != 6 {
+		t.Fail()
+	}
+}
+
+func TestGivenACenterSqueezePluralityIsTied(t *testing.T) {
+	actual := Plurality{}.Tally(testCandidates(), centerSqueezeBallots(), EarliestSuggestion{})
+
+	if actual.Winner == nil || actual.Winner.ID != 1 || len(actual.Ties) != 1 {
+		t.Fail()
+	}
+}
+
+func TestGivenAnUnknownMethodNewTallierErrors(t *testing.T) {
+	if _, err := NewTallier("approval"); err == nil {
+		t.Fail()
+	}
+
+	if _, err := NewTallier("Schulze"); err != nil {
+		t.Fail()
+	}
+}
@@ -0,0 +1,103 @@
+package vote
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+)
+
+const (
+	EarliestTieBreak   = "earliest"
+	FewestWinsTieBreak = "fewest-wins"
+	RandomTieBreak     = "random"
+)
+
+// TieBreaker decides between candidates a tally could not separate.
+type TieBreaker interface {
+	// Order sorts the tied candidates from most to least favoured.
+	Order(tied []Candidate) []Candidate
+	// Rule describes how the tie was broken for the tally output.
+	Rule() string
+}
+
+// NewTieBreaker looks up the tie-break strategy by the name used in the app
+// configuration and the --tie-break flag. The week seeds the random draw so
+// anyone can reproduce it.
+func NewTieBreaker(name string, week general.WeekID) (TieBreaker, error) {
+	switch strings.ToLower(name) {
+	case EarliestTieBreak:
+		return EarliestSuggestion{}, nil
+	case FewestWinsTieBreak:
+		return FewestPriorWins{}, nil
+	case RandomTieBreak:
+		return SeededDraw{Seed: int64(week.IsoYear*100 + week.IsoWeek)}, nil
+	}
+
+	return nil, fmt.Errorf("unknown tie-break \"%s\", expected one of %s",
+		name, strings.Join([]string{EarliestTieBreak, FewestWinsTieBreak, RandomTieBreak}, ", "))
+}
+
+// EarliestSuggestion favours the movie that was suggested first.
+type EarliestSuggestion struct{}
+
+func (EarliestSuggestion) Order(tied []Candidate) []Candidate {
+	ordered := byID(tied)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].DateAdded.Before(ordered[j].DateAdded)
+	})
+
+	return ordered
+}
+
+func (EarliestSuggestion) Rule() string {
+	return "earliest suggestion"
+}
+
+// FewestPriorWins favours the movie whose author has won movie night the
+// fewest times, so picks get spread around the group. Authors with the same
+// number of wins fall back to the earliest suggestion.
+type FewestPriorWins struct{}
+
+func (FewestPriorWins) Order(tied []Candidate) []Candidate {
+	ordered := EarliestSuggestion{}.Order(tied)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].PriorWins < ordered[j].PriorWins
+	})
+
+	return ordered
+}
+
+func (FewestPriorWins) Rule() string {
+	return "fewest prior wins"
+}
+
+// SeededDraw shuffles the tied movies with a fixed seed. Given the same seed
+// and the same tied movies the draw always comes out the same.
+type SeededDraw struct {
+	Seed int64
+}
+
+func (draw SeededDraw) Order(tied []Candidate) []Candidate {
+	ordered := byID(tied)
+	random := rand.New(rand.NewSource(draw.Seed))
+	random.Shuffle(len(ordered), func(i, j int) {
+		ordered[i], ordered[j] = ordered[j], ordered[i]
+	})
+
+	return ordered
+}
+
+func (draw SeededDraw) Rule() string {
+	return fmt.Sprintf("random draw seeded with %d", draw.Seed)
+}
+
+func byID(candidates []Candidate) []Candidate {
+	ordered := make([]Candidate, len(candidates))
+	copy(ordered, candidates)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].ID < ordered[j].ID })
+
+	return ordered
+}
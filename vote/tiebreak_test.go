@@ -0,0 +1,62 @@
+package vote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+)
+
+func TestGivenTiedCandidatesEarliestSuggestionFavoursFirstAdded(t *testing.T) {
+	candidates := testCandidates()
+	candidates[2].DateAdded = time.Date(2021, 5, 24, 0, 0, 0, 0, time.UTC)
+	candidates[0].DateAdded = time.Date(2021, 5, 25, 0, 0, 0, 0, time.UTC)
+	candidates[1].DateAdded = time.Date(2021, 5, 25, 0, 0, 0, 0, time.UTC)
+
+	actual := EarliestSuggestion{}.Order(candidates)
+
+	if actual[0].ID != 3 || actual[1].ID != 1 || actual[2].ID != 2 {
+		t.Fail()
+	}
+}
+
+func TestGivenTiedCandidatesFewestPriorWinsFavoursAuthorsWhoWonLess(t *testing.T) {
+	candidates := testCandidates()
+	candidates[0].PriorWins = 2
+	candidates[1].PriorWins = 0
+	candidates[2].PriorWins = 1
+
+	actual := FewestPriorWins{}.Order(candidates)
+
+	if actual[0].ID != 2 || actual[1].ID != 3 || actual[2].ID != 1 {
+		t.Fail()
+	}
+}
+
+func TestGivenTheSameWeekSeededDrawIsReproducible(t *testing.T) {
+	week := general.WeekID{IsoYear: 2021, IsoWeek: 21}
+	first, _ := NewTieBreaker(RandomTieBreak, week)
+	second, _ := NewTieBreaker(RandomTieBreak, week)
+
+	candidates := testCandidates()
+	reversed := []Candidate{candidates[2], candidates[1], candidates[0]}
+
+	expected := first.Order(candidates)
+	actual := second.Order(reversed)
+
+	for i := range expected {
+		if expected[i].ID != actual[i].ID {
+			t.Fail()
+		}
+	}
+
+	if first.Rule() != "random draw seeded with 202121" {
+		t.Fail()
+	}
+}
+
+func TestGivenAnUnknownRuleNewTieBreakerErrors(t *testing.T) {
+	if _, err := NewTieBreaker("coin-flip", general.WeekID{}); err == nil {
+		t.Fail()
+	}
+}
@@ -0,0 +1,58 @@
+package vote
+
+import (
+	"context"
+
+	"github.com/fredlawl/200-colony-movie-night-bot/general"
+	"github.com/fredlawl/200-colony-movie-night-bot/suggestion"
+)
+
+// RecordWinner counts the ballots of the settings' week with the configured
+// method and tie-break and records the winner as watched when the week's
+// movie night started. The winner is nil when nobody voted. Recording a week
+// again fails with ErrWinnerRecorded, so tallies with other methods or late
+// votes don't rewrite history. See general.WeekSettings for past weeks.
+func RecordWinner(ctx context.Context, votes Store, seconds suggestion.SecondStore, settings *general.AppSettings) (*Candidate, error) {
+	cfg := settings.Config
+	week := settings.WeekID
+
+	tallier, err := NewTallier(cfg.TallyMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	ties, err := NewTieBreaker(cfg.TieBreak, week)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := votes.Candidates(ctx, week)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err = onBallot(ctx, seconds, week, cfg.NominationThreshold, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	ballots, err := votes.Ballots(ctx, week)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ballots) == 0 {
+		return nil, nil
+	}
+
+	result := tallier.Tally(candidates, ballots, ties)
+	if result.Winner == nil {
+		return nil, nil
+	}
+
+	if err := votes.SaveWinner(ctx, week, *result.Winner, cfg.TallyMethod, settings.CurCycle.VotingCloses); err != nil {
+		return nil, err
+	}
+
+	return result.Winner, nil
+}